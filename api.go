@@ -27,6 +27,9 @@
 //	send.mask:"email"        - Mask on send
 //	send.redact:"***"        - Redact on send
 //
+// store.encrypt and load.decrypt must be paired with the same algorithm;
+// mark intentional one-way fields "aes,writeonly" or "aes,readonly".
+//
 // # Basic Usage
 //
 //	type User struct {
@@ -58,7 +61,7 @@
 //
 // Capabilities are constrained to predefined constants:
 //
-//   - EncryptAlgo: EncryptAES, EncryptRSA, EncryptEnvelope
+//   - EncryptAlgo: EncryptAES, EncryptRSA, EncryptEnvelope, EncryptXChaCha, EncryptX25519, EncryptMLKEM, EncryptSubject, EncryptDerived
 //   - HashAlgo: HashArgon2, HashBcrypt, HashSHA256, HashSHA512
 //   - MaskType: MaskSSN, MaskEmail, MaskPhone, MaskCard, MaskIP, MaskUUID, MaskIBAN, MaskName
 //
@@ -95,6 +98,18 @@
 //   - AES(key) - AES-GCM symmetric encryption
 //   - RSA(pub, priv) - RSA-OAEP asymmetric encryption
 //   - Envelope(masterKey) - Envelope encryption with per-message data keys
+//   - XChaCha(key) - XChaCha20-Poly1305 symmetric encryption
+//   - X25519(pub, priv) - ECIES with ephemeral X25519, HKDF-SHA256, and ChaCha20-Poly1305
+//   - MLKEM(pub, priv) - Hybrid post-quantum ML-KEM-768 + X25519 encryption
+//   - Tenant(tenantOf, resolve) - Per-tenant encryptor selected from the context
+//   - Subject(store) - Per-subject keys for crypto-shredding, keyed by the cereal:"subject" field
+//   - Derived(masterKey) - Per-field AES-GCM keys derived with HKDF-SHA256
+//   - NewKeyring(primary, keys) - Several keys by ID, for rotation
+//
+// Key import: FromPEM (PKCS#1, PKCS#8, SPKI), FromJWK, and FromJWKSet (a Keyring by kid).
+//
+// Key sources: EnvKeySource, FileKeySource, and DirKeySource yield key material;
+// a KeyReloader polls one and swaps in rebuilt encryptors when it changes.
 //
 // # Hash Algorithms
 //
@@ -105,6 +120,13 @@
 //   - SHA256Hasher() - SHA-256 deterministic hashing
 //   - SHA512Hasher() - SHA-512 deterministic hashing
 //
+// Argon2 and Bcrypt also implement Verifier; Processor.Verify checks a
+// password attempt against a hashed field, and Processor.NeedsRehash
+// reports hashes made with outdated parameters.
+//
+// A HashLimiter caps the memory concurrent password hashes may use, queueing
+// the rest and failing with ErrHashSaturated when it cannot admit them.
+//
 // # Masking
 //
 // Built-in content-aware maskers:
@@ -1,7 +1,13 @@
 package bson
 
 import (
+	"context"
+	"encoding/base64"
 	"testing"
+	"unicode/utf8"
+
+	"github.com/zoobzio/cereal"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func TestNew(t *testing.T) {
@@ -305,3 +311,54 @@ func TestUnmarshal_ExtraData(t *testing.T) {
 		t.Errorf("Unmarshal Name = %q, want %q", restored.Name, "test")
 	}
 }
+
+// secretRecord has an encrypted string field.
+type secretRecord struct {
+	ID    string `bson:"id"`
+	Email string `bson:"email" store.encrypt:"aes" load.decrypt:"aes"`
+}
+
+func (r secretRecord) Clone() secretRecord { return r }
+
+func TestProcessor_BinaryEncodingRoundTrip(t *testing.T) {
+	// BSON strings must be UTF-8, so EncodingBinary falls back to base64.
+	if _, ok := New().(cereal.BinaryCodec); ok {
+		t.Fatal("BSON codec must not carry binary strings")
+	}
+
+	enc, err := cereal.AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	if err != nil {
+		t.Fatalf("AES() error: %v", err)
+	}
+	proc, err := cereal.NewProcessor[secretRecord]()
+	if err != nil {
+		t.Fatalf("NewProcessor() error: %v", err)
+	}
+	proc.SetCodec(New()).SetEncryptor(cereal.EncryptAES, enc).SetCiphertextEncoding(cereal.EncodingBinary)
+
+	ctx := context.Background()
+	data, err := proc.Write(ctx, &secretRecord{ID: "1", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+
+	var raw bson.M
+	if err := bson.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	stored, _ := raw["email"].(string)
+	if !utf8.ValidString(stored) {
+		t.Error("stored ciphertext is not valid UTF-8")
+	}
+	if _, err := base64.StdEncoding.DecodeString(stored); err != nil {
+		t.Errorf("stored ciphertext is not base64: %q", stored)
+	}
+
+	got, err := proc.Read(ctx, data)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if got.Email != "alice@example.com" {
+		t.Errorf("Email = %q, want %q", got.Email, "alice@example.com")
+	}
+}
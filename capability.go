@@ -13,6 +13,21 @@ const (
 
 	// EncryptEnvelope uses envelope encryption with per-message data keys.
 	EncryptEnvelope EncryptAlgo = "envelope"
+
+	// EncryptXChaCha uses XChaCha20-Poly1305 symmetric encryption.
+	EncryptXChaCha EncryptAlgo = "xchacha"
+
+	// EncryptX25519 uses ECIES with ephemeral X25519 asymmetric encryption.
+	EncryptX25519 EncryptAlgo = "x25519"
+
+	// EncryptMLKEM uses hybrid post-quantum ML-KEM-768 + X25519 asymmetric encryption.
+	EncryptMLKEM EncryptAlgo = "mlkem"
+
+	// EncryptSubject uses per-subject AES-GCM keys for crypto-shredding.
+	EncryptSubject EncryptAlgo = "subject"
+
+	// EncryptDerived uses per-field AES-GCM keys derived from a master key.
+	EncryptDerived EncryptAlgo = "derived"
 )
 
 // HashAlgo represents a supported hashing algorithm.
@@ -40,6 +55,11 @@ var validEncryptAlgos = map[EncryptAlgo]bool{
 	EncryptAES:      true,
 	EncryptRSA:      true,
 	EncryptEnvelope: true,
+	EncryptXChaCha:  true,
+	EncryptX25519:   true,
+	EncryptMLKEM:    true,
+	EncryptSubject:  true,
+	EncryptDerived:  true,
 }
 
 // validHashAlgos contains all valid hash algorithms for tag validation.
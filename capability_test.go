@@ -10,6 +10,9 @@ func TestIsValidEncryptAlgo(t *testing.T) {
 		{EncryptAES, true},
 		{EncryptRSA, true},
 		{EncryptEnvelope, true},
+		{EncryptXChaCha, true},
+		{EncryptX25519, true},
+		{EncryptMLKEM, true},
 		{"unknown", false},
 		{"", false},
 	}
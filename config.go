@@ -0,0 +1,111 @@
+package cereal
+
+import (
+	"errors"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// processorConfig is an immutable snapshot of a processor's handlers and
+// settings. Configuration methods publish a modified copy, so an operation
+// keeps using the snapshot it started with and never waits for a change.
+type processorConfig struct {
+	codec      Codec
+	encryptors map[EncryptAlgo]Encryptor
+	hashers    map[HashAlgo]Hasher
+	maskers    map[MaskType]Masker
+	now        func() time.Time
+	encoding   CiphertextEncoding
+	selfTest   bool
+	closed     bool
+
+	// Shared by snapshots with the same handlers; replaced when they change
+	validation *validation
+}
+
+// validation caches the result of validating one set of handlers.
+// Self-test failures wrapping ErrEncrypt are operational, such as an
+// unreachable KMS, and are retried by the next operation instead.
+type validation struct {
+	mu   sync.Mutex
+	done atomic.Bool
+	err  error
+}
+
+// run returns the cached result, or calls validate and caches its result.
+func (v *validation) run(validate func() error) error {
+	if v.done.Load() {
+		return v.err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.done.Load() {
+		return v.err
+	}
+	err := validate()
+	if !errors.Is(err, ErrEncrypt) {
+		v.err = err
+		v.done.Store(true)
+	}
+	return err
+}
+
+// newProcessorConfig returns the initial configuration: builtin hashers and
+// maskers, no encryptors, and no codec.
+func newProcessorConfig() *processorConfig {
+	return &processorConfig{
+		encryptors: make(map[EncryptAlgo]Encryptor),
+		hashers:    builtinHashers(),
+		maskers:    builtinMaskers(),
+		now:        time.Now,
+		encoding:   EncodingStd,
+		validation: &validation{},
+	}
+}
+
+// clone returns a copy to modify before publishing. The handler maps are
+// copied; the cached validation is kept until invalidate is called.
+func (c *processorConfig) clone() *processorConfig {
+	next := *c
+	next.encryptors = maps.Clone(c.encryptors)
+	next.hashers = maps.Clone(c.hashers)
+	next.maskers = maps.Clone(c.maskers)
+	return &next
+}
+
+// invalidate discards the cached validation, so the next operation
+// re-validates against the new handlers.
+func (c *processorConfig) invalidate() {
+	c.validation = &validation{}
+}
+
+// contentType returns the codec content type or empty string if no codec is set.
+func (c *processorConfig) contentType() string {
+	if c.codec != nil {
+		return c.codec.ContentType()
+	}
+	return ""
+}
+
+// ciphertextEncoding returns the encoding for a plan's string values: the
+// field's enc option, else the processor's. EncodingBinary falls back to
+// EncodingStd unless the codec carries binary strings.
+func (c *processorConfig) ciphertextEncoding(plan *processorFieldPlan) CiphertextEncoding {
+	enc := c.encoding
+	if plan.opts != nil && plan.opts.encoding != "" {
+		enc = plan.opts.encoding
+	}
+	if enc == EncodingBinary && !c.binaryStrings() {
+		return EncodingStd
+	}
+	return enc
+}
+
+// binaryStrings reports whether the codec carries arbitrary bytes in
+// strings, so string fields may hold raw ciphertext.
+func (c *processorConfig) binaryStrings() bool {
+	bc, ok := c.codec.(BinaryCodec)
+	return ok && bc.BinaryStrings()
+}
@@ -0,0 +1,113 @@
+package cereal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingHasher blocks in Hash until released, standing in for a slow
+// password hasher.
+type blockingHasher struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (h *blockingHasher) Hash([]byte) (string, error) {
+	close(h.entered)
+	<-h.release
+	return "slow", nil
+}
+
+func TestProcessor_ConfigureDuringOperation(t *testing.T) {
+	slow := &blockingHasher{entered: make(chan struct{}), release: make(chan struct{})}
+	proc, _ := NewProcessor[HashUser]()
+	proc.SetHasher(HashSHA256, slow)
+
+	type result struct {
+		user HashUser
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		u, err := proc.Receive(context.Background(), HashUser{Password: "secret"})
+		done <- result{u, err}
+	}()
+	<-slow.entered
+
+	// Reconfiguring must not wait for the in-flight hash.
+	configured := make(chan struct{})
+	go func() {
+		enc, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+		proc.SetEncryptor(EncryptAES, enc).SetCodec(&testCodec{}).SetHasher(HashSHA256, SHA256Hasher())
+		close(configured)
+	}()
+	select {
+	case <-configured:
+	case <-time.After(5 * time.Second):
+		t.Fatal("configuration blocked behind an in-flight operation")
+	}
+
+	// New operations use the new configuration while the old one is still running.
+	next, err := proc.Receive(context.Background(), HashUser{Password: "secret"})
+	if err != nil || next.Password == "slow" {
+		t.Errorf("Receive() with new hasher = %q, %v", next.Password, err)
+	}
+
+	// The in-flight operation finishes with the snapshot it started with.
+	close(slow.release)
+	r := <-done
+	if r.err != nil || r.user.Password != "slow" {
+		t.Errorf("in-flight Receive() = %q, %v", r.user.Password, r.err)
+	}
+}
+
+func TestProcessor_CloseWaitsForOperations(t *testing.T) {
+	slow := &blockingHasher{entered: make(chan struct{}), release: make(chan struct{})}
+	proc, _ := NewProcessor[HashUser]()
+	proc.SetHasher(HashSHA256, slow)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := proc.Receive(context.Background(), HashUser{Password: "secret"})
+		done <- err
+	}()
+	<-slow.entered
+
+	closed := make(chan struct{})
+	go func() {
+		_ = proc.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatal("Close() returned before the in-flight operation finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(slow.release)
+	if err := <-done; err != nil {
+		t.Errorf("in-flight Receive() error: %v", err)
+	}
+	<-closed
+	if _, err := proc.Receive(context.Background(), HashUser{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Receive() after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestProcessorConfig_CloneKeepsValidation(t *testing.T) {
+	cfg := newProcessorConfig()
+	next := cfg.clone()
+	if next.validation != cfg.validation {
+		t.Error("clone() should share the cached validation")
+	}
+	next.hashers[HashAlgo("custom")] = SHA256Hasher()
+	if _, ok := cfg.hashers["custom"]; ok {
+		t.Error("clone() should copy the handler maps")
+	}
+	next.invalidate()
+	if next.validation == cfg.validation {
+		t.Error("invalidate() should replace the cached validation")
+	}
+}
@@ -0,0 +1,161 @@
+package cereal
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// derivedInfo prefixes the HKDF info for per-field keys. v2 keys types by
+// package path as well as name.
+const derivedInfo = "cereal field key v2"
+
+// errNoFieldContext indicates a derived encryptor was used without field context.
+var errNoFieldContext = errors.New("no field in context")
+
+// FieldInfo identifies the field being transformed.
+type FieldInfo struct {
+	Type  string // Package-qualified Go type name (e.g., "example.com/app.User")
+	Field string // Field path (e.g., "Address.Street")
+}
+
+// fieldContextKey is the context key for the current FieldInfo.
+type fieldContextKey struct{}
+
+// WithField returns a context carrying the field being transformed.
+// The Processor sets it for every value it encrypts or decrypts one at a
+// time; call it directly when using a field-aware encryptor on its own.
+func WithField(ctx context.Context, typeName, field string) context.Context {
+	return context.WithValue(ctx, fieldContextKey{}, FieldInfo{Type: typeName, Field: field})
+}
+
+// FieldFromContext returns the field set by WithField.
+func FieldFromContext(ctx context.Context) (FieldInfo, bool) {
+	info, ok := ctx.Value(fieldContextKey{}).(FieldInfo)
+	return info, ok
+}
+
+// batchFieldsKey is the context key for the fields of a batch, in value order.
+type batchFieldsKey struct{}
+
+// withBatchFields returns a context carrying the field of each batch value.
+func withBatchFields(ctx context.Context, fields []FieldInfo) context.Context {
+	return context.WithValue(ctx, batchFieldsKey{}, fields)
+}
+
+// batchValueContext returns the context for value i of a batch, restoring
+// its FieldInfo for encryptors that fall back to per-value calls.
+func batchValueContext(ctx context.Context, i int) context.Context {
+	fields, ok := ctx.Value(batchFieldsKey{}).([]FieldInfo)
+	if !ok || i >= len(fields) {
+		return ctx
+	}
+	return WithField(ctx, fields[i].Type, fields[i].Field)
+}
+
+// derivedEncryptor encrypts each field with its own HKDF-derived AES key.
+type derivedEncryptor struct {
+	masterKey []byte
+
+	mu    sync.RWMutex
+	aeads map[FieldInfo]cipher.AEAD
+}
+
+// Derived returns an encryptor that derives a separate AES-256-GCM key for
+// each (type, field path) from a 32-byte master key with HKDF-SHA256.
+//
+// A nonce collision or key compromise in one field does not affect other
+// fields, without managing more than one key. Derived keys are cached.
+//
+// The field comes from the operation's context, which the Processor fills
+// in with the type's package path and name, so same-named types in
+// different packages get different keys. Renaming or moving the type, or
+// renaming the field, changes its key, so existing ciphertext must be
+// re-encrypted. Encrypt and Decrypt have no context and always fail.
+//
+// Format: [12 bytes nonce][ciphertext]
+func Derived(masterKey []byte) (Encryptor, error) {
+	if len(masterKey) != 32 {
+		return nil, fmt.Errorf("%w: master key must be 32 bytes", ErrInvalidKey)
+	}
+	return &derivedEncryptor{
+		masterKey: append([]byte(nil), masterKey...),
+		aeads:     make(map[FieldInfo]cipher.AEAD),
+	}, nil
+}
+
+func (e *derivedEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	return e.EncryptContext(context.Background(), plaintext)
+}
+
+func (e *derivedEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	return e.DecryptContext(context.Background(), ciphertext)
+}
+
+func (e *derivedEncryptor) EncryptContext(ctx context.Context, plaintext []byte) ([]byte, error) {
+	aead, err := e.aead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sealWith(aead, plaintext)
+}
+
+func (e *derivedEncryptor) DecryptContext(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	aead, err := e.aead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return openWith(aead, ciphertext)
+}
+
+// aead returns the cached AEAD for the field in ctx, deriving it on first use.
+func (e *derivedEncryptor) aead(ctx context.Context) (cipher.AEAD, error) {
+	info, ok := FieldFromContext(ctx)
+	if !ok {
+		return nil, errNoFieldContext
+	}
+
+	e.mu.RLock()
+	aead, ok := e.aeads[info]
+	e.mu.RUnlock()
+	if ok {
+		return aead, nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.masterKey == nil {
+		return nil, ErrClosed
+	}
+	if aead, ok := e.aeads[info]; ok {
+		return aead, nil
+	}
+
+	// NUL separators keep ("a", "b.c") and ("a.b", "c") distinct.
+	key, err := hkdf.Key(sha256.New, e.masterKey, nil, derivedInfo+"\x00"+info.Type+"\x00"+info.Field, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive field key: %w", err)
+	}
+	defer zeroize(key)
+
+	aead, err = newAEAD(EncryptAES, key)
+	if err != nil {
+		return nil, err
+	}
+
+	e.aeads[info] = aead
+	return aead, nil
+}
+
+// Destroy zeroes the master key and drops derived keys.
+func (e *derivedEncryptor) Destroy() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	zeroize(e.masterKey)
+	e.masterKey = nil
+	clear(e.aeads)
+}
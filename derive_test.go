@@ -0,0 +1,172 @@
+package cereal
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+var derivedMasterKey = []byte("master-key-for-derived-fields-32")
+
+// DerivedUser has two fields encrypted under the same master key.
+type DerivedUser struct {
+	Email string   `json:"email" store.encrypt:"derived" load.decrypt:"derived"`
+	Phone string   `json:"phone" store.encrypt:"derived" load.decrypt:"derived"`
+	Tags  []string `json:"tags" store.encrypt:"derived" load.decrypt:"derived"`
+}
+
+func (u DerivedUser) Clone() DerivedUser {
+	c := u
+	c.Tags = append([]string(nil), u.Tags...)
+	return c
+}
+
+func TestDerived_InvalidKey(t *testing.T) {
+	if _, err := Derived([]byte("short")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestDerived_RoundTrip(t *testing.T) {
+	enc, err := Derived(derivedMasterKey)
+	if err != nil {
+		t.Fatalf("Derived() error: %v", err)
+	}
+	proc, _ := NewProcessor[DerivedUser]()
+	proc.SetEncryptor(EncryptDerived, enc)
+
+	ctx := context.Background()
+	original := DerivedUser{Email: testEmail, Phone: "555-0100", Tags: []string{"a", "b"}}
+	stored, err := proc.Store(ctx, original)
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	loaded, err := proc.Load(ctx, stored)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if loaded.Email != original.Email || loaded.Phone != original.Phone || loaded.Tags[1] != "b" {
+		t.Errorf("round trip mismatch: %+v", loaded)
+	}
+}
+
+func TestDerived_SeparatesFields(t *testing.T) {
+	enc, _ := Derived(derivedMasterKey)
+	proc, _ := NewProcessor[DerivedUser]()
+	proc.SetEncryptor(EncryptDerived, enc)
+
+	ctx := context.Background()
+	stored, err := proc.Store(ctx, DerivedUser{Email: testEmail, Phone: "555-0100"})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+
+	// Ciphertext from one field must not decrypt as another.
+	stored.Phone = stored.Email
+	if _, err := proc.Load(ctx, stored); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt for swapped field, got %v", err)
+	}
+}
+
+func TestDerived_SeparatesTypes(t *testing.T) {
+	enc, _ := Derived(derivedMasterKey)
+	ec := enc.(EncryptorContext)
+
+	ct, err := ec.EncryptContext(WithField(context.Background(), "User", "Email"), []byte("secret"))
+	if err != nil {
+		t.Fatalf("EncryptContext() error: %v", err)
+	}
+	if _, err := ec.DecryptContext(WithField(context.Background(), "Order", "Email"), ct); err == nil {
+		t.Error("expected decryption under another type's key to fail")
+	}
+	pt, err := ec.DecryptContext(WithField(context.Background(), "User", "Email"), ct)
+	if err != nil || string(pt) != "secret" {
+		t.Errorf("DecryptContext() = %q, %v", pt, err)
+	}
+}
+
+func TestDerived_QualifiedTypeName(t *testing.T) {
+	enc, _ := Derived(derivedMasterKey)
+	ec := enc.(EncryptorContext)
+	proc, _ := NewProcessor[DerivedUser]()
+	proc.SetEncryptor(EncryptDerived, enc)
+
+	stored, err := proc.Store(context.Background(), DerivedUser{Email: testEmail})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	ct, err := base64.StdEncoding.DecodeString(stored.Email)
+	if err != nil {
+		t.Fatalf("decode ciphertext: %v", err)
+	}
+
+	// The processor keys by package path, so a same-named type elsewhere
+	// sharing the master key cannot read the field.
+	if _, err := ec.DecryptContext(WithField(context.Background(), "DerivedUser", "Email"), ct); err == nil {
+		t.Error("expected the bare type name to derive a different key")
+	}
+	if _, err := ec.DecryptContext(WithField(context.Background(), "example.com/other.DerivedUser", "Email"), ct); err == nil {
+		t.Error("expected another package's type to derive a different key")
+	}
+	pt, err := ec.DecryptContext(WithField(context.Background(), "github.com/zoobzio/cereal.DerivedUser", "Email"), ct)
+	if err != nil || string(pt) != testEmail {
+		t.Errorf("DecryptContext() with qualified name = %q, %v", pt, err)
+	}
+}
+
+func TestDerived_RequiresField(t *testing.T) {
+	enc, _ := Derived(derivedMasterKey)
+	if _, err := enc.Encrypt([]byte("secret")); !errors.Is(err, errNoFieldContext) {
+		t.Errorf("expected errNoFieldContext, got %v", err)
+	}
+}
+
+func TestDerived_ThroughTenantBatch(t *testing.T) {
+	// Tenant forwards batches; per-value field context must survive its fallback.
+	tenant := Tenant(tenantFromContext, func(context.Context, string) (Encryptor, error) {
+		return Derived(derivedMasterKey)
+	})
+	proc, _ := NewProcessor[DerivedUser]()
+	proc.SetEncryptor(EncryptDerived, tenant)
+
+	ctx := withTenant("acme")
+	stored, err := proc.Store(ctx, DerivedUser{Email: testEmail, Phone: "555-0100"})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+
+	direct, _ := Derived(derivedMasterKey)
+	verify, _ := NewProcessor[DerivedUser]()
+	verify.SetEncryptor(EncryptDerived, direct)
+	loaded, err := verify.Load(context.Background(), stored)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if loaded.Phone != "555-0100" {
+		t.Errorf("Phone = %q, want %q", loaded.Phone, "555-0100")
+	}
+}
+
+func TestDerived_Destroy(t *testing.T) {
+	enc, _ := Derived(derivedMasterKey)
+	ctx := WithField(context.Background(), "User", "Email")
+	if _, err := encryptContext(ctx, enc, []byte("secret")); err != nil {
+		t.Fatalf("EncryptContext() error: %v", err)
+	}
+
+	d, ok := enc.(*derivedEncryptor)
+	if !ok {
+		t.Fatal("expected *derivedEncryptor")
+	}
+	master := d.masterKey
+	d.Destroy()
+
+	if !bytes.Equal(master, make([]byte, len(master))) {
+		t.Error("master key should be zeroized")
+	}
+	if _, err := encryptContext(ctx, enc, []byte("secret")); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed after Destroy, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package cereal
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// CiphertextEncoding selects how ciphertext is stored in string fields.
+// []byte fields always hold raw ciphertext.
+type CiphertextEncoding string
+
+const (
+	// EncodingStd uses padded standard base64 (default).
+	EncodingStd CiphertextEncoding = "std"
+
+	// EncodingURL uses padded URL-safe base64.
+	EncodingURL CiphertextEncoding = "url"
+
+	// EncodingRaw uses unpadded standard base64.
+	EncodingRaw CiphertextEncoding = "raw"
+
+	// EncodingRawURL uses unpadded URL-safe base64, suitable for tokens in URLs.
+	EncodingRawURL CiphertextEncoding = "rawurl"
+
+	// EncodingHex uses lowercase hexadecimal.
+	EncodingHex CiphertextEncoding = "hex"
+
+	// EncodingBinary stores raw ciphertext bytes in the string, avoiding
+	// text-encoding overhead. It applies only when the processor's codec
+	// implements BinaryCodec; otherwise EncodingStd is used.
+	EncodingBinary CiphertextEncoding = "binary"
+)
+
+// validCiphertextEncodings contains all valid ciphertext encodings.
+var validCiphertextEncodings = map[CiphertextEncoding]bool{
+	EncodingStd:    true,
+	EncodingURL:    true,
+	EncodingRaw:    true,
+	EncodingRawURL: true,
+	EncodingHex:    true,
+	EncodingBinary: true,
+}
+
+// IsValidCiphertextEncoding checks if the given encoding is supported.
+func IsValidCiphertextEncoding(e CiphertextEncoding) bool {
+	return validCiphertextEncodings[e]
+}
+
+// BinaryCodec is implemented by codecs whose string values can carry
+// arbitrary bytes (e.g., MessagePack). Such codecs may use EncodingBinary.
+type BinaryCodec interface {
+	// BinaryStrings reports whether strings round-trip arbitrary bytes.
+	BinaryStrings() bool
+}
+
+// encode returns ciphertext in this encoding. EncodingBinary must be
+// resolved by the caller; it is treated as std here.
+func (e CiphertextEncoding) encode(ciphertext []byte) string {
+	switch e {
+	case EncodingURL:
+		return base64.URLEncoding.EncodeToString(ciphertext)
+	case EncodingRaw:
+		return base64.RawStdEncoding.EncodeToString(ciphertext)
+	case EncodingRawURL:
+		return base64.RawURLEncoding.EncodeToString(ciphertext)
+	case EncodingHex:
+		return hex.EncodeToString(ciphertext)
+	default:
+		return base64.StdEncoding.EncodeToString(ciphertext)
+	}
+}
+
+// decodeCiphertext decodes a string field's ciphertext, detecting the
+// encoding so values written with different encodings can be loaded
+// together. Hex is tried first: real base64 ciphertext is vanishingly
+// unlikely to use only hex digits. Base64 padding is optional and the
+// alphabet selects standard or URL-safe. If binary is true, as it is for
+// any codec with binary strings whatever the current encoding, strings
+// that are not valid text encodings are returned as raw ciphertext.
+func decodeCiphertext(s string, binary bool) ([]byte, error) {
+	if isHex(s) {
+		return hex.DecodeString(s)
+	}
+
+	trimmed := strings.TrimRight(s, "=")
+	enc := base64.RawStdEncoding
+	if strings.ContainsAny(trimmed, "-_") {
+		enc = base64.RawURLEncoding
+	}
+
+	decoded, err := enc.DecodeString(trimmed)
+	if err != nil && binary {
+		return []byte(s), nil
+	}
+	return decoded, err
+}
+
+// isHex reports whether s is an even-length string of hex digits.
+func isHex(s string) bool {
+	if len(s)%2 != 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+	return true
+}
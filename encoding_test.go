@@ -0,0 +1,166 @@
+package cereal
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+)
+
+// binaryCodec is a testCodec whose strings carry arbitrary bytes.
+type binaryCodec struct{ testCodec }
+
+func (c *binaryCodec) BinaryStrings() bool { return true }
+
+func TestDecodeCiphertext_DetectsEncoding(t *testing.T) {
+	ciphertext := []byte("\xfb\xff\xfe ciphertext bytes \x00\x01")
+
+	for _, enc := range []CiphertextEncoding{EncodingStd, EncodingURL, EncodingRaw, EncodingRawURL, EncodingHex} {
+		t.Run(string(enc), func(t *testing.T) {
+			got, err := decodeCiphertext(enc.encode(ciphertext), false)
+			if err != nil {
+				t.Fatalf("decodeCiphertext() error: %v", err)
+			}
+			if string(got) != string(ciphertext) {
+				t.Errorf("decodeCiphertext() = %x, want %x", got, ciphertext)
+			}
+		})
+	}
+}
+
+func TestDecodeCiphertext_Invalid(t *testing.T) {
+	_, err := decodeCiphertext("not-valid-base64!!!", false)
+	if err == nil || !strings.Contains(err.Error(), "base64") {
+		t.Errorf("expected base64 error, got %v", err)
+	}
+
+	got, err := decodeCiphertext("raw\xff!bytes", true)
+	if err != nil || string(got) != "raw\xff!bytes" {
+		t.Errorf("binary fallback = %q, %v", got, err)
+	}
+}
+
+func TestSetCiphertextEncoding(t *testing.T) {
+	enc, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	proc, err := NewProcessor[EncryptUser]()
+	if err != nil {
+		t.Fatalf("NewProcessor() error: %v", err)
+	}
+	proc.SetEncryptor(EncryptAES, enc).SetCiphertextEncoding(EncodingRawURL)
+
+	ctx := context.Background()
+	stored, err := proc.Store(ctx, EncryptUser{ID: "1", Email: testEmail})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	if _, err := base64.RawURLEncoding.DecodeString(stored.Email); err != nil {
+		t.Errorf("expected rawurl ciphertext, got %q: %v", stored.Email, err)
+	}
+
+	// Decryption detects the encoding regardless of the current setting.
+	proc.SetCiphertextEncoding(EncodingStd)
+	loaded, err := proc.Load(ctx, stored)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if loaded.Email != testEmail {
+		t.Errorf("Email = %q, want %q", loaded.Email, testEmail)
+	}
+}
+
+// HexUser stores Token as hex and Note with a TTL, decrypted with an
+// enc-only load.decrypt tag.
+type HexUser struct {
+	Token string `json:"token" store.encrypt:"aes,enc=hex" load.decrypt:"aes"`
+	Note  string `json:"note" store.encrypt:"aes,ttl=1h" load.decrypt:"aes,enc=hex"`
+}
+
+func (u HexUser) Clone() HexUser { return u }
+
+func TestProcessor_FieldEncoding(t *testing.T) {
+	enc, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	proc, err := NewProcessor[HexUser]()
+	if err != nil {
+		t.Fatalf("NewProcessor() error: %v", err)
+	}
+	proc.SetEncryptor(EncryptAES, enc)
+
+	ctx := context.Background()
+	stored, err := proc.Store(ctx, HexUser{Token: "tok", Note: "note"})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	if _, err := hex.DecodeString(stored.Token); err != nil {
+		t.Errorf("expected hex ciphertext, got %q", stored.Token)
+	}
+
+	// The enc option alone does not frame, so Note still inherits the TTL frame.
+	loaded, err := proc.Load(ctx, stored)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if loaded.Token != "tok" || loaded.Note != "note" {
+		t.Errorf("round trip mismatch: %+v", loaded)
+	}
+
+	proc.SetClock(func() time.Time { return time.Now().Add(2 * time.Hour) })
+	if _, err := proc.Load(ctx, stored); err == nil {
+		t.Error("expected expired Note to fail")
+	}
+}
+
+func TestProcessor_BinaryEncoding(t *testing.T) {
+	enc, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	ctx := context.Background()
+
+	t.Run("binary codec", func(t *testing.T) {
+		proc, _ := NewProcessor[EncryptUser]()
+		proc.SetEncryptor(EncryptAES, enc).SetCodec(&binaryCodec{}).SetCiphertextEncoding(EncodingBinary)
+
+		stored, err := proc.Store(ctx, EncryptUser{ID: "1", Email: testEmail})
+		if err != nil {
+			t.Fatalf("Store() error: %v", err)
+		}
+		if want := len(testEmail) + 28; len(stored.Email) != want {
+			t.Errorf("binary ciphertext length = %d, want %d", len(stored.Email), want)
+		}
+		loaded, err := proc.Load(ctx, stored)
+		if err != nil {
+			t.Fatalf("Load() error: %v", err)
+		}
+		if loaded.Email != testEmail {
+			t.Errorf("Email = %q, want %q", loaded.Email, testEmail)
+		}
+
+		// Switching encodings keeps earlier binary values readable.
+		proc.SetCiphertextEncoding(EncodingStd)
+		if loaded, err := proc.Load(ctx, stored); err != nil || loaded.Email != testEmail {
+			t.Errorf("Load() after switching to std = %q, %v", loaded.Email, err)
+		}
+	})
+
+	t.Run("text codec falls back to std", func(t *testing.T) {
+		proc, _ := NewProcessor[EncryptUser]()
+		proc.SetEncryptor(EncryptAES, enc).SetCodec(&testCodec{}).SetCiphertextEncoding(EncodingBinary)
+
+		stored, err := proc.Store(ctx, EncryptUser{ID: "1", Email: testEmail})
+		if err != nil {
+			t.Fatalf("Store() error: %v", err)
+		}
+		if _, err := base64.StdEncoding.DecodeString(stored.Email); err != nil {
+			t.Errorf("expected std base64 ciphertext, got %q", stored.Email)
+		}
+	})
+}
+
+func TestParseEncryptTag_Encoding(t *testing.T) {
+	_, opts, err := parseEncryptTag("aes,enc=rawurl")
+	if err != nil || opts.encoding != EncodingRawURL || opts.framed() {
+		t.Errorf("parseEncryptTag(aes,enc=rawurl) = %+v, %v", opts, err)
+	}
+	if _, _, err := parseEncryptTag("aes,enc=base32"); err == nil {
+		t.Error("expected error for unknown encoding")
+	}
+}
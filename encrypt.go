@@ -1,6 +1,7 @@
 package cereal
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
@@ -9,6 +10,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sync/atomic"
+
+	"golang.org/x/crypto/chacha20poly1305"
 )
 
 // Encryption-specific errors (extend the base sentinel errors).
@@ -20,55 +24,137 @@ var (
 // Encryptor handles encryption/decryption operations.
 type Encryptor interface {
 	// Encrypt encrypts plaintext and returns ciphertext.
+	// Callers may zeroize plaintext once Encrypt returns, so it must not be
+	// retained. Returning it, or a slice of it, as the ciphertext is allowed:
+	// the Processor keeps buffers the ciphertext shares.
 	Encrypt(plaintext []byte) ([]byte, error)
 
 	// Decrypt decrypts ciphertext and returns plaintext.
+	// The returned slice must not be retained: the Processor zeroizes it
+	// once the plaintext is copied into a string field.
 	Decrypt(ciphertext []byte) ([]byte, error)
 }
 
-// aesEncryptor implements AES-GCM encryption.
-type aesEncryptor struct {
-	gcm cipher.AEAD
+// EncryptorContext is implemented by encryptors that honor the operation's
+// context (deadlines, cancellation, tenant or trace values).
+//
+// The Processor prefers these methods over Encrypt/Decrypt when available and
+// passes through the ctx given to Store, Load, Write, or Read.
+type EncryptorContext interface {
+	// EncryptContext encrypts plaintext and returns ciphertext.
+	EncryptContext(ctx context.Context, plaintext []byte) ([]byte, error)
+
+	// DecryptContext decrypts ciphertext and returns plaintext.
+	DecryptContext(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// BatchEncryptor is implemented by encryptors that can process many values
+// in one call, such as those backed by a remote key service.
+//
+// During Store and Load the Processor collects every value for an algorithm
+// (scalar fields, slice elements and map values) and passes them in a single
+// call. Results must be returned in the same order and with the same length
+// as the input. An error fails the whole batch.
+type BatchEncryptor interface {
+	// EncryptBatch encrypts each plaintext and returns the ciphertexts.
+	EncryptBatch(ctx context.Context, plaintexts [][]byte) ([][]byte, error)
+
+	// DecryptBatch decrypts each ciphertext and returns the plaintexts.
+	DecryptBatch(ctx context.Context, ciphertexts [][]byte) ([][]byte, error)
+}
+
+// encryptContext encrypts with enc, preferring EncryptorContext.
+func encryptContext(ctx context.Context, enc Encryptor, plaintext []byte) ([]byte, error) {
+	if ec, ok := enc.(EncryptorContext); ok {
+		return ec.EncryptContext(ctx, plaintext)
+	}
+	return enc.Encrypt(plaintext)
+}
+
+// decryptContext decrypts with enc, preferring EncryptorContext.
+func decryptContext(ctx context.Context, enc Encryptor, ciphertext []byte) ([]byte, error) {
+	if ec, ok := enc.(EncryptorContext); ok {
+		return ec.DecryptContext(ctx, ciphertext)
+	}
+	return enc.Decrypt(ciphertext)
+}
+
+// KeyCapabilities is implemented by encryptors that may hold only part of a
+// key pair (e.g., a public key without its private key).
+//
+// Validate uses it to reject store.encrypt fields bound to an encryptor that
+// cannot encrypt, and load.decrypt fields bound to one that cannot decrypt.
+// Encryptors that don't implement it are assumed to support both operations.
+type KeyCapabilities interface {
+	// CanEncrypt reports whether the encryptor holds key material for encryption.
+	CanEncrypt() bool
+
+	// CanDecrypt reports whether the encryptor holds key material for decryption.
+	CanDecrypt() bool
+}
+
+// Destroyer is implemented by encryptors (and hashers) that hold key
+// material they can wipe.
+//
+// Destroy zeroes the key bytes the encryptor owns and drops cached ciphers.
+// Afterwards, operations fail with ErrClosed. Processor.Close calls it on
+// every registered encryptor and hasher.
+//
+// Go offers no way to wipe key schedules held inside crypto/aes or
+// chacha20poly1305, so ciphers are dropped rather than cleared. Encryptors
+// that keep no key bytes of their own (AES, XChaCha, RSA, X25519, MLKEM)
+// do not implement it.
+type Destroyer interface {
+	Destroy()
+}
+
+// aeadEncryptor implements nonce-prefixed AEAD encryption.
+// It backs both the AES-GCM and XChaCha20-Poly1305 encryptors.
+type aeadEncryptor struct {
+	aead cipher.AEAD
 }
 
 // AES returns an AES-GCM encryptor.
 // Key must be 16, 24, or 32 bytes for AES-128, AES-192, or AES-256.
 func AES(key []byte) (Encryptor, error) {
-	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
-		return nil, fmt.Errorf("%w: must be 16, 24, or 32 bytes, got %d", ErrInvalidKey, len(key))
-	}
-
-	block, err := aes.NewCipher(key)
+	aead, err := newAEAD(EncryptAES, key)
 	if err != nil {
 		return nil, err
 	}
+	return &aeadEncryptor{aead: aead}, nil
+}
 
-	gcm, err := cipher.NewGCM(block)
+// XChaCha returns an XChaCha20-Poly1305 encryptor.
+// Key must be 32 bytes.
+//
+// XChaCha20-Poly1305 uses 192-bit random nonces, so a single key can safely
+// encrypt far more messages than AES-GCM before nonce collisions become a concern.
+func XChaCha(key []byte) (Encryptor, error) {
+	aead, err := newAEAD(EncryptXChaCha, key)
 	if err != nil {
 		return nil, err
 	}
-
-	return &aesEncryptor{gcm: gcm}, nil
+	return &xchachaEncryptor{aeadEncryptor{aead: aead}}, nil
 }
 
-func (e *aesEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
-	nonce := make([]byte, e.gcm.NonceSize())
+func (e *aeadEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize())
 	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return nil, err
 	}
 
 	// Prepend nonce to ciphertext
-	return e.gcm.Seal(nonce, nonce, plaintext, nil), nil
+	return e.aead.Seal(nonce, nonce, plaintext, nil), nil
 }
 
-func (e *aesEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
-	nonceSize := e.gcm.NonceSize()
+func (e *aeadEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	nonceSize := e.aead.NonceSize()
 	if len(ciphertext) < nonceSize {
 		return nil, ErrCiphertextShort
 	}
 
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := e.gcm.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := e.aead.Open(nil, nonce, ciphertext, nil)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrDecrypt, err)
 	}
@@ -76,100 +162,318 @@ func (e *aesEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
 	return plaintext, nil
 }
 
-// rsaEncryptor implements RSA-OAEP encryption.
+// newAEAD constructs the AEAD cipher for a symmetric algorithm.
+// Supported algorithms are EncryptAES and EncryptXChaCha.
+func newAEAD(algo EncryptAlgo, key []byte) (cipher.AEAD, error) {
+	switch algo {
+	case EncryptAES:
+		if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+			return nil, fmt.Errorf("%w: must be 16, 24, or 32 bytes, got %d", ErrInvalidKey, len(key))
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+
+	case EncryptXChaCha:
+		if len(key) != chacha20poly1305.KeySize {
+			return nil, fmt.Errorf("%w: must be %d bytes, got %d", ErrInvalidKey, chacha20poly1305.KeySize, len(key))
+		}
+		return chacha20poly1305.NewX(key)
+
+	default:
+		return nil, fmt.Errorf("%w: unsupported data cipher %q", ErrInvalidKey, algo)
+	}
+}
+
+// rsaEncryptor implements hybrid RSA-OAEP encryption.
+// A random data key is encrypted with RSA-OAEP and the payload with AES-GCM,
+// so plaintext size is not limited by the RSA modulus.
 type rsaEncryptor struct {
 	pub  *rsa.PublicKey
 	priv *rsa.PrivateKey
 }
 
-// RSA returns an RSA-OAEP encryptor.
+// RSA returns a hybrid RSA-OAEP encryptor.
 // pub is required for encryption; priv is required for decryption.
 // Either can be nil if only one operation is needed.
+//
+// Each message gets a random AES-256 data key wrapped with RSA-OAEP (SHA-256),
+// and the payload is sealed with AES-GCM. Ciphertexts produced by earlier
+// versions (raw RSA-OAEP) are still decrypted.
 func RSA(pub *rsa.PublicKey, priv *rsa.PrivateKey) Encryptor {
 	return &rsaEncryptor{pub: pub, priv: priv}
 }
 
 func (e *rsaEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
 	if e.pub == nil {
-		return nil, errors.New("public key required for encryption")
+		return nil, fmt.Errorf("%w: public key required", ErrEncryptUnsupported)
+	}
+
+	// Generate random data key
+	dataKey := make([]byte, 32)
+	defer zeroize(dataKey)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+
+	// Encrypt plaintext with data key
+	encryptedData, err := sealAEAD(EncryptAES, dataKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypt data key with public key
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, e.pub, dataKey, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	return rsa.EncryptOAEP(sha256.New(), rand.Reader, e.pub, plaintext, nil)
+	return frameKey(encryptedKey, encryptedData)
 }
 
 func (e *rsaEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
 	if e.priv == nil {
-		return nil, errors.New("private key required for decryption")
+		return nil, fmt.Errorf("%w: private key required", ErrDecryptUnsupported)
+	}
+
+	// Legacy format: raw RSA-OAEP ciphertext is exactly the modulus size.
+	// Framed ciphertexts are always longer (length prefix, nonce, and tag).
+	if len(ciphertext) == e.priv.Size() {
+		return rsa.DecryptOAEP(sha256.New(), rand.Reader, e.priv, ciphertext, nil)
+	}
+
+	encryptedKey, encryptedData, err := unframeKey(ciphertext)
+	if err != nil {
+		return nil, err
 	}
 
-	return rsa.DecryptOAEP(sha256.New(), rand.Reader, e.priv, ciphertext, nil)
+	dataKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, e.priv, encryptedKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decrypt data key: %w", ErrDecrypt, err)
+	}
+	defer zeroize(dataKey)
+
+	return openAEAD(EncryptAES, dataKey, encryptedData)
 }
 
+// CanEncrypt reports whether a public key is configured.
+func (e *rsaEncryptor) CanEncrypt() bool { return e.pub != nil }
+
+// CanDecrypt reports whether a private key is configured.
+func (e *rsaEncryptor) CanDecrypt() bool { return e.priv != nil }
+
 // envelopeEncryptor implements envelope encryption.
-// A random data key is generated per operation, encrypted with the master key,
-// and prepended to the ciphertext.
+// A random data key is generated per operation, wrapped with a key-encryption
+// key, and prepended to the ciphertext.
 type envelopeEncryptor struct {
-	masterGCM   cipher.AEAD
+	wrapper     KeyWrapper
+	dataCipher  EncryptAlgo
 	dataKeySize int
+	cache       *dataKeyCache // nil when caching is disabled
+	destroyed   atomic.Bool
+}
+
+// EnvelopeConfig configures envelope encryption.
+type EnvelopeConfig struct {
+	// DataCipher selects the AEAD used with per-message data keys.
+	// Supported values are EncryptAES (default) and EncryptXChaCha.
+	DataCipher EncryptAlgo
+
+	// Cache enables reuse of data keys across messages.
+	// The zero value disables caching: every message gets a fresh data key.
+	Cache DataKeyCacheConfig
 }
 
 // Envelope returns an envelope encryptor using a master key.
 // Master key must be 16, 24, or 32 bytes.
 func Envelope(masterKey []byte) (Encryptor, error) {
-	if len(masterKey) != 16 && len(masterKey) != 24 && len(masterKey) != 32 {
-		return nil, fmt.Errorf("%w: must be 16, 24, or 32 bytes, got %d", ErrInvalidKey, len(masterKey))
+	return EnvelopeWithConfig(masterKey, EnvelopeConfig{})
+}
+
+// EnvelopeWithConfig returns an envelope encryptor with custom configuration.
+// Master key must be 16, 24, or 32 bytes and wraps data keys with AES-GCM.
+//
+// Ciphertexts are only readable by an envelope encryptor configured with the
+// same DataCipher.
+func EnvelopeWithConfig(masterKey []byte, cfg EnvelopeConfig) (Encryptor, error) {
+	wrapper, err := AESKeyWrapper(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return EnvelopeWithWrapper(wrapper, cfg)
+}
+
+// EnvelopeWithWrapper returns an envelope encryptor that wraps data keys with
+// the given KeyWrapper instead of an in-memory master key.
+//
+// Use this to keep the key-encryption key in a KMS or HSM. Combine with
+// EnvelopeConfig.Cache to avoid one KeyWrapper call per field.
+func EnvelopeWithWrapper(wrapper KeyWrapper, cfg EnvelopeConfig) (Encryptor, error) {
+	if wrapper == nil {
+		return nil, fmt.Errorf("%w: key wrapper required", ErrInvalidKey)
+	}
+
+	dataCipher := cfg.DataCipher
+	if dataCipher == "" {
+		dataCipher = EncryptAES
+	}
+	if dataCipher != EncryptAES && dataCipher != EncryptXChaCha {
+		return nil, fmt.Errorf("%w: unsupported data cipher %q", ErrInvalidKey, dataCipher)
+	}
+
+	e := &envelopeEncryptor{
+		wrapper:     wrapper,
+		dataCipher:  dataCipher,
+		dataKeySize: 32, // AES-256 or XChaCha20 data keys
+	}
+	if cfg.Cache.enabled() {
+		e.cache = newDataKeyCache(cfg.Cache)
+	}
+
+	return e, nil
+}
+
+func (e *envelopeEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	return e.EncryptContext(context.Background(), plaintext)
+}
+
+func (e *envelopeEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	return e.DecryptContext(context.Background(), ciphertext)
+}
+
+// EncryptContext encrypts plaintext, passing ctx to the KeyWrapper.
+func (e *envelopeEncryptor) EncryptContext(ctx context.Context, plaintext []byte) ([]byte, error) {
+	dk, err := e.encryptionKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if e.cache == nil {
+		defer zeroize(dk.key)
 	}
 
-	block, err := aes.NewCipher(masterKey)
+	// Encrypt plaintext with data key
+	encryptedData, err := sealWith(dk.aead, plaintext)
 	if err != nil {
 		return nil, err
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	return frameKey(dk.wrapped, encryptedData)
+}
+
+// DecryptContext decrypts ciphertext, passing ctx to the KeyWrapper.
+func (e *envelopeEncryptor) DecryptContext(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	encryptedKey, encryptedData, err := unframeKey(ciphertext)
 	if err != nil {
 		return nil, err
 	}
 
-	return &envelopeEncryptor{
-		masterGCM:   gcm,
-		dataKeySize: 32, // AES-256 data keys
-	}, nil
+	dataAEAD, err := e.decryptionKey(ctx, encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decrypt data with data key
+	return openWith(dataAEAD, encryptedData)
 }
 
-func (e *envelopeEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+// encryptionKey returns a data key for encryption, reusing a cached key
+// while it remains within the configured message and age limits.
+func (e *envelopeEncryptor) encryptionKey(ctx context.Context) (*cachedDataKey, error) {
+	if e.cache != nil {
+		if dk := e.cache.acquire(); dk != nil {
+			return dk, nil
+		}
+	}
+
+	dk, err := e.generateDataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.cache != nil {
+		e.cache.install(dk)
+	}
+
+	return dk, nil
+}
+
+// generateDataKey creates and wraps a fresh data key.
+func (e *envelopeEncryptor) generateDataKey(ctx context.Context) (*cachedDataKey, error) {
+	if e.destroyed.Load() {
+		return nil, ErrClosed
+	}
+
 	// Generate random data key
 	dataKey := make([]byte, e.dataKeySize)
 	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
 		return nil, err
 	}
 
-	// Encrypt plaintext with data key
-	dataBlock, err := aes.NewCipher(dataKey)
+	dataAEAD, err := newAEAD(e.dataCipher, dataKey)
 	if err != nil {
+		zeroize(dataKey)
 		return nil, err
 	}
 
-	dataGCM, err := cipher.NewGCM(dataBlock)
+	// Wrap data key with key-encryption key
+	wrapped, err := e.wrapper.WrapKey(ctx, dataKey)
 	if err != nil {
-		return nil, err
+		zeroize(dataKey)
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
 	}
 
-	dataNonce := make([]byte, dataGCM.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, dataNonce); err != nil {
-		return nil, err
+	return &cachedDataKey{key: dataKey, wrapped: wrapped, aead: dataAEAD}, nil
+}
+
+// decryptionKey returns the AEAD for a wrapped data key, consulting the
+// cache before calling the KeyWrapper.
+func (e *envelopeEncryptor) decryptionKey(ctx context.Context, wrapped []byte) (cipher.AEAD, error) {
+	if e.destroyed.Load() {
+		return nil, ErrClosed
 	}
 
-	encryptedData := dataGCM.Seal(dataNonce, dataNonce, plaintext, nil)
+	var digest [sha256.Size]byte
+	if e.cache != nil {
+		digest = sha256.Sum256(wrapped)
+		if dataAEAD := e.cache.lookup(digest); dataAEAD != nil {
+			return dataAEAD, nil
+		}
+	}
 
-	// Encrypt data key with master key
-	masterNonce := make([]byte, e.masterGCM.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, masterNonce); err != nil {
-		return nil, err
+	// Unwrap data key with key-encryption key
+	dataKey, err := e.wrapper.UnwrapKey(ctx, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decrypt data key: %w", ErrDecrypt, err)
 	}
 
-	encryptedKey := e.masterGCM.Seal(masterNonce, masterNonce, dataKey, nil)
+	dataAEAD, err := newAEAD(e.dataCipher, dataKey)
+	if err != nil || e.cache == nil {
+		zeroize(dataKey)
+		return dataAEAD, err
+	}
+
+	e.cache.store(&cachedDataKey{digest: digest, key: dataKey, aead: dataAEAD})
+	return dataAEAD, nil
+}
+
+// Destroy zeroes cached data keys and destroys the KeyWrapper if it
+// implements Destroyer.
+func (e *envelopeEncryptor) Destroy() {
+	e.destroyed.Store(true)
+	if e.cache != nil {
+		e.cache.purge()
+	}
+	if d, ok := e.wrapper.(Destroyer); ok {
+		d.Destroy()
+	}
+}
 
-	// Format: [2 bytes key len][encrypted key][encrypted data]
+// frameKey joins an encrypted data key and encrypted payload.
+// Format: [2 bytes key len][encrypted key][encrypted data]
+func frameKey(encryptedKey, encryptedData []byte) ([]byte, error) {
 	if len(encryptedKey) > 65535 {
 		return nil, errors.New("encrypted key exceeds maximum length")
 	}
@@ -183,46 +487,52 @@ func (e *envelopeEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
 	return result, nil
 }
 
-func (e *envelopeEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+// unframeKey splits a framed ciphertext into its encrypted data key and payload.
+func unframeKey(ciphertext []byte) (encryptedKey, encryptedData []byte, err error) {
 	if len(ciphertext) < 2 {
-		return nil, ErrCiphertextShort
+		return nil, nil, ErrCiphertextShort
 	}
 
 	// Parse key length
 	keyLen := int(uint16(ciphertext[0])<<8 | uint16(ciphertext[1]))
 	if len(ciphertext) < 2+keyLen {
-		return nil, ErrCiphertextShort
-	}
-
-	encryptedKey := ciphertext[2 : 2+keyLen]
-	encryptedData := ciphertext[2+keyLen:]
-
-	// Decrypt data key with master key
-	masterNonceSize := e.masterGCM.NonceSize()
-	if len(encryptedKey) < masterNonceSize {
-		return nil, ErrCiphertextShort
+		return nil, nil, ErrCiphertextShort
 	}
 
-	masterNonce := encryptedKey[:masterNonceSize]
-	encryptedKey = encryptedKey[masterNonceSize:]
+	return ciphertext[2 : 2+keyLen], ciphertext[2+keyLen:], nil
+}
 
-	dataKey, err := e.masterGCM.Open(nil, masterNonce, encryptedKey, nil)
+// sealAEAD encrypts a payload under a data key, prepending a random nonce.
+func sealAEAD(algo EncryptAlgo, dataKey, plaintext []byte) ([]byte, error) {
+	dataAEAD, err := newAEAD(algo, dataKey)
 	if err != nil {
-		return nil, fmt.Errorf("%w: failed to decrypt data key: %w", ErrDecrypt, err)
+		return nil, err
 	}
+	return sealWith(dataAEAD, plaintext)
+}
 
-	// Decrypt data with data key
-	dataBlock, err := aes.NewCipher(dataKey)
-	if err != nil {
+// sealWith encrypts a payload with an AEAD, prepending a random nonce.
+func sealWith(dataAEAD cipher.AEAD, plaintext []byte) ([]byte, error) {
+	dataNonce := make([]byte, dataAEAD.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, dataNonce); err != nil {
 		return nil, err
 	}
 
-	dataGCM, err := cipher.NewGCM(dataBlock)
+	return dataAEAD.Seal(dataNonce, dataNonce, plaintext, nil), nil
+}
+
+// openAEAD decrypts a nonce-prefixed payload sealed under a data key.
+func openAEAD(algo EncryptAlgo, dataKey, encryptedData []byte) ([]byte, error) {
+	dataAEAD, err := newAEAD(algo, dataKey)
 	if err != nil {
 		return nil, err
 	}
+	return openWith(dataAEAD, encryptedData)
+}
 
-	dataNonceSize := dataGCM.NonceSize()
+// openWith decrypts a nonce-prefixed payload with an AEAD.
+func openWith(dataAEAD cipher.AEAD, encryptedData []byte) ([]byte, error) {
+	dataNonceSize := dataAEAD.NonceSize()
 	if len(encryptedData) < dataNonceSize {
 		return nil, ErrCiphertextShort
 	}
@@ -230,7 +540,7 @@ func (e *envelopeEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
 	dataNonce := encryptedData[:dataNonceSize]
 	encryptedData = encryptedData[dataNonceSize:]
 
-	plaintext, err := dataGCM.Open(nil, dataNonce, encryptedData, nil)
+	plaintext, err := dataAEAD.Open(nil, dataNonce, encryptedData, nil)
 	if err != nil {
 		return nil, fmt.Errorf("%w: failed to decrypt data: %w", ErrDecrypt, err)
 	}
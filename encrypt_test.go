@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
 	"testing"
 )
 
@@ -95,6 +97,39 @@ func TestRSA_DecryptWithoutPrivateKey(t *testing.T) {
 	if err == nil {
 		t.Error("expected error when decrypting without private key")
 	}
+	if !errors.Is(err, ErrDecryptUnsupported) {
+		t.Errorf("expected ErrDecryptUnsupported, got %v", err)
+	}
+}
+
+func TestRSA_KeyCapabilities(t *testing.T) {
+	priv, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	tests := []struct {
+		name        string
+		enc         Encryptor
+		wantEncrypt bool
+		wantDecrypt bool
+	}{
+		{"full", RSA(&priv.PublicKey, priv), true, true},
+		{"public only", RSA(&priv.PublicKey, nil), true, false},
+		{"private only", RSA(nil, priv), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kc, ok := tt.enc.(KeyCapabilities)
+			if !ok {
+				t.Fatal("RSA encryptor should implement KeyCapabilities")
+			}
+			if kc.CanEncrypt() != tt.wantEncrypt {
+				t.Errorf("CanEncrypt() = %v, want %v", kc.CanEncrypt(), tt.wantEncrypt)
+			}
+			if kc.CanDecrypt() != tt.wantDecrypt {
+				t.Errorf("CanDecrypt() = %v, want %v", kc.CanDecrypt(), tt.wantDecrypt)
+			}
+		})
+	}
 }
 
 func TestEnvelope_RoundTrip(t *testing.T) {
@@ -139,3 +174,150 @@ func TestEnvelope_DifferentDataKeys(t *testing.T) {
 		t.Error("same plaintext should produce different ciphertext (random data key)")
 	}
 }
+
+func TestXChaCha_RoundTrip(t *testing.T) {
+	key := []byte("32-byte-key-for-xchacha20-poly!!")
+	enc, err := XChaCha(key)
+	if err != nil {
+		t.Fatalf("XChaCha() error: %v", err)
+	}
+
+	plaintext := []byte("hello, world!")
+	ciphertext, err := enc.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+
+	// 24-byte nonce + 16-byte tag
+	if len(ciphertext) != len(plaintext)+24+16 {
+		t.Errorf("ciphertext length = %d, want %d", len(ciphertext), len(plaintext)+40)
+	}
+
+	decrypted, err := enc.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt() error: %v", err)
+	}
+
+	if !bytes.Equal(plaintext, decrypted) {
+		t.Errorf("round-trip failed: got %q, want %q", decrypted, plaintext)
+	}
+}
+
+func TestXChaCha_InvalidKeySize(t *testing.T) {
+	_, err := XChaCha([]byte("16-byte-aes-key!"))
+	if !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestXChaCha_TamperedCiphertext(t *testing.T) {
+	enc, _ := XChaCha([]byte("32-byte-key-for-xchacha20-poly!!"))
+
+	ciphertext, _ := enc.Encrypt([]byte("hello"))
+	ciphertext[len(ciphertext)-1] ^= 0xff
+
+	_, err := enc.Decrypt(ciphertext)
+	if !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt, got %v", err)
+	}
+
+	_, err = enc.Decrypt(ciphertext[:10])
+	if !errors.Is(err, ErrCiphertextShort) {
+		t.Errorf("expected ErrCiphertextShort, got %v", err)
+	}
+}
+
+func TestEnvelopeWithConfig_XChaCha(t *testing.T) {
+	masterKey := []byte("32-byte-master-key-for-envelope!")
+	enc, err := EnvelopeWithConfig(masterKey, EnvelopeConfig{DataCipher: EncryptXChaCha})
+	if err != nil {
+		t.Fatalf("EnvelopeWithConfig() error: %v", err)
+	}
+
+	plaintext := []byte("hello, world!")
+	ciphertext, err := enc.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+
+	decrypted, err := enc.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt() error: %v", err)
+	}
+
+	if !bytes.Equal(plaintext, decrypted) {
+		t.Errorf("round-trip failed: got %q, want %q", decrypted, plaintext)
+	}
+
+	// An AES-data envelope with the same master key cannot read XChaCha data
+	aesEnv, _ := Envelope(masterKey)
+	if _, err := aesEnv.Decrypt(ciphertext); err == nil {
+		t.Error("expected error decrypting XChaCha envelope with AES data cipher")
+	}
+}
+
+func TestEnvelopeWithConfig_UnsupportedCipher(t *testing.T) {
+	masterKey := []byte("32-byte-master-key-for-envelope!")
+	_, err := EnvelopeWithConfig(masterKey, EnvelopeConfig{DataCipher: EncryptRSA})
+	if !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestRSA_LargePlaintext(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error: %v", err)
+	}
+
+	enc := RSA(&priv.PublicKey, priv)
+
+	// Far beyond the ~190 byte OAEP limit for a 2048-bit key
+	plaintext := bytes.Repeat([]byte("123 Main Street, Springfield. "), 200)
+	ciphertext, err := enc.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+
+	decrypted, err := enc.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt() error: %v", err)
+	}
+
+	if !bytes.Equal(plaintext, decrypted) {
+		t.Error("round-trip failed for large plaintext")
+	}
+}
+
+func TestRSA_DecryptLegacyOAEP(t *testing.T) {
+	priv, _ := rsa.GenerateKey(rand.Reader, 2048)
+	enc := RSA(&priv.PublicKey, priv)
+
+	plaintext := []byte("legacy")
+	legacy, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, &priv.PublicKey, plaintext, nil)
+	if err != nil {
+		t.Fatalf("EncryptOAEP() error: %v", err)
+	}
+
+	decrypted, err := enc.Decrypt(legacy)
+	if err != nil {
+		t.Fatalf("Decrypt() error: %v", err)
+	}
+
+	if !bytes.Equal(plaintext, decrypted) {
+		t.Errorf("legacy decrypt failed: got %q, want %q", decrypted, plaintext)
+	}
+}
+
+func TestRSA_TamperedCiphertext(t *testing.T) {
+	priv, _ := rsa.GenerateKey(rand.Reader, 2048)
+	enc := RSA(&priv.PublicKey, priv)
+
+	ciphertext, _ := enc.Encrypt([]byte("hello"))
+	ciphertext[len(ciphertext)-1] ^= 0xff
+
+	_, err := enc.Decrypt(ciphertext)
+	if !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt, got %v", err)
+	}
+}
@@ -46,6 +46,45 @@ var (
 
 	// ErrMissingCodec indicates a codec operation was called without a configured codec.
 	ErrMissingCodec = errors.New("missing codec")
+
+	// ErrEncryptUnsupported indicates an encryptor cannot encrypt (e.g., no public key).
+	ErrEncryptUnsupported = errors.New("encryption not supported")
+
+	// ErrDecryptUnsupported indicates an encryptor cannot decrypt (e.g., no private key).
+	ErrDecryptUnsupported = errors.New("decryption not supported")
+
+	// ErrUnknownTenant indicates no tenant was found in the context or the tenant has no key.
+	ErrUnknownTenant = errors.New("unknown tenant")
+
+	// ErrMissingSubject indicates a subject-keyed operation had no subject in its context.
+	ErrMissingSubject = errors.New("missing subject")
+
+	// ErrSelfTestSkipped indicates a self-test could not round-trip a field's
+	// encryptor, so the field's key is untested.
+	ErrSelfTestSkipped = errors.New("self-test skipped")
+
+	// ErrKeyShredded indicates the subject's data key was deleted and its data is unrecoverable.
+	ErrKeyShredded = errors.New("key shredded")
+
+	// ErrExpired indicates a value encrypted with a TTL was loaded after it expired.
+	ErrExpired = errors.New("ciphertext expired")
+
+	// ErrUnpairedTag indicates a store.encrypt field without a matching load.decrypt,
+	// or the reverse, or the two tags naming different algorithms.
+	ErrUnpairedTag = errors.New("unpaired encryption tag")
+
+	// ErrHashSaturated indicates a HashLimiter could not admit a hash: its queue
+	// was full, or the context ended or MaxWait elapsed while waiting for budget.
+	ErrHashSaturated = errors.New("hasher saturated")
+
+	// ErrVerifyUnsupported indicates a hasher cannot verify hashes (e.g., sha256).
+	ErrVerifyUnsupported = errors.New("verification not supported")
+
+	// ErrInvalidHash indicates a stored hash is malformed or from another algorithm.
+	ErrInvalidHash = errors.New("invalid hash")
+
+	// ErrClosed indicates an operation on a Processor or encryptor after Close or Destroy.
+	ErrClosed = errors.New("closed")
 )
 
 // ConfigError represents a processor configuration error.
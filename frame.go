@@ -0,0 +1,359 @@
+package cereal
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Inner frame layout, sealed inside the encryptor's authenticated plaintext:
+//
+//	[1 byte version][1 byte flags][8 bytes expiry, if set][payload][padding]
+//
+// Expiry is Unix seconds, big-endian, rounded up. A compressed payload is raw DEFLATE.
+// Padding follows ISO/IEC 7816-4: a 0x80 byte followed by zeros.
+const (
+	frameVersion byte = 1
+	frameHeader       = 2
+	frameExpiry       = 8
+
+	framePadded     byte = 1 << 0
+	frameExpires    byte = 1 << 1
+	frameCompressed byte = 1 << 2
+
+	frameFlags = framePadded | frameExpires | frameCompressed
+)
+
+// defaultCompressMin is the smallest plaintext the compress option compresses.
+// Below this, DEFLATE overhead usually outweighs the savings.
+const defaultCompressMin = 1024
+
+// maxInflateRatio bounds how much a compressed payload may expand. frame
+// leaves payloads that compress better than this uncompressed, so unframe
+// can reject larger expansions as a decompression bomb.
+const maxInflateRatio = 256
+
+// errBadFrame indicates decrypted plaintext is not a valid inner frame.
+var errBadFrame = errors.New("malformed plaintext frame")
+
+// minPadBucket is the smallest power-of-two bucket, so short values
+// (empty, single digits) share one size.
+const minPadBucket = 16
+
+// Padding selects the bucket size plaintext is padded to before encryption.
+// The zero value disables padding.
+type Padding struct {
+	pow2  bool
+	block int
+}
+
+// PadPowerOfTwo pads plaintext up to the next power of two (minimum 16 bytes).
+// Ciphertext length then reveals only the plaintext's size class.
+func PadPowerOfTwo() Padding {
+	return Padding{pow2: true}
+}
+
+// PadBlock pads plaintext up to a multiple of size bytes.
+// A size of zero or less disables padding.
+func PadBlock(size int) Padding {
+	if size <= 0 {
+		return Padding{}
+	}
+	return Padding{block: size}
+}
+
+func (p Padding) enabled() bool {
+	return p.pow2 || p.block > 0
+}
+
+// padTo returns the padded length for n bytes, reserving one byte for the 0x80 marker.
+func (p Padding) padTo(n int) int {
+	n++
+	if p.pow2 {
+		if n <= minPadBucket {
+			return minPadBucket
+		}
+		return 1 << bits.Len(uint(n-1))
+	}
+	return (n + p.block - 1) / p.block * p.block
+}
+
+// encryptOptions are per-field or per-encryptor transforms applied to
+// plaintext inside the encryption.
+type encryptOptions struct {
+	pad         Padding
+	ttl         time.Duration
+	compress    bool
+	compressMin int                // smallest plaintext to compress
+	encoding    CiphertextEncoding // string field encoding; empty uses the processor's
+	writeOnly   bool               // store.encrypt without load.decrypt is intended
+	readOnly    bool               // load.decrypt without store.encrypt is intended
+}
+
+// framed reports whether plaintext is wrapped in the inner frame.
+// The enc option only changes the outer text encoding.
+func (o *encryptOptions) framed() bool {
+	return o != nil && (o.pad.enabled() || o.ttl > 0 || o.compress)
+}
+
+// sameFrame reports whether o and other frame plaintext identically.
+func (o *encryptOptions) sameFrame(other *encryptOptions) bool {
+	if !o.framed() || !other.framed() {
+		return o.framed() == other.framed()
+	}
+	return o.pad == other.pad && o.ttl == other.ttl &&
+		o.compress == other.compress && o.compressMin == other.compressMin
+}
+
+// transforms reports whether o changes the ciphertext, through framing or
+// encoding. The pairing opt-outs do not.
+func (o *encryptOptions) transforms() bool {
+	return o.framed() || (o != nil && o.encoding != "")
+}
+
+// parseEncryptTag splits a store.encrypt or load.decrypt tag value into the
+// algorithm and its options, e.g. "aes,pad=pow2" or "aes,enc=hex".
+func parseEncryptTag(val string) (string, *encryptOptions, error) {
+	algo, rest, found := strings.Cut(val, ",")
+	if !found {
+		return algo, nil, nil
+	}
+
+	opts := &encryptOptions{}
+	for _, opt := range strings.Split(rest, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch key {
+		case "pad":
+			if value == "pow2" {
+				opts.pad = PadPowerOfTwo()
+				continue
+			}
+			size, err := strconv.Atoi(value)
+			if err != nil || size <= 0 {
+				return "", nil, fmt.Errorf("invalid pad %q", value)
+			}
+			opts.pad = PadBlock(size)
+		case "ttl":
+			ttl, err := time.ParseDuration(value)
+			if err != nil || ttl <= 0 {
+				return "", nil, fmt.Errorf("invalid ttl %q", value)
+			}
+			opts.ttl = ttl
+		case "compress":
+			opts.compress = true
+			opts.compressMin = defaultCompressMin
+			if value == "" {
+				continue
+			}
+			size, err := strconv.Atoi(value)
+			if err != nil || size < 0 {
+				return "", nil, fmt.Errorf("invalid compress threshold %q", value)
+			}
+			opts.compressMin = size
+		case "enc":
+			if !IsValidCiphertextEncoding(CiphertextEncoding(value)) {
+				return "", nil, fmt.Errorf("invalid encoding %q", value)
+			}
+			opts.encoding = CiphertextEncoding(value)
+		case "writeonly":
+			opts.writeOnly = true
+		case "readonly":
+			opts.readOnly = true
+		default:
+			return "", nil, fmt.Errorf("unknown option %q", key)
+		}
+	}
+	return algo, opts, nil
+}
+
+// frame wraps plaintext in the inner frame. now is the time a TTL counts from.
+func (o *encryptOptions) frame(plaintext []byte, now time.Time) []byte {
+	var flags byte
+	if o.compress && len(plaintext) >= o.compressMin {
+		if compressed, ok := deflate(plaintext); ok {
+			defer zeroize(compressed)
+			flags |= frameCompressed
+			plaintext = compressed
+		}
+	}
+
+	size := frameHeader + len(plaintext)
+	if o.ttl > 0 {
+		flags |= frameExpires
+		size += frameExpiry
+	}
+	if o.pad.enabled() {
+		flags |= framePadded
+		size = o.pad.padTo(size)
+	}
+
+	out := make([]byte, frameHeader, size)
+	out[0] = frameVersion
+	out[1] = flags
+	if o.ttl > 0 {
+		// Round up to whole seconds, so a value never expires before its TTL.
+		expires := now.Add(o.ttl)
+		if truncated := expires.Truncate(time.Second); !truncated.Equal(expires) {
+			expires = truncated.Add(time.Second)
+		}
+		out = binary.BigEndian.AppendUint64(out, uint64(expires.Unix())) // #nosec G115 -- Unix times after 1970 are non-negative
+	}
+	out = append(out, plaintext...)
+
+	if o.pad.enabled() {
+		out = append(out, 0x80)
+		out = out[:size] // remaining bytes are already zero
+	}
+	return out
+}
+
+// unframe validates the inner frame and returns the payload.
+// It returns ErrExpired if the frame's expiry is at or before now.
+// A compressed payload is rejected unless o enables compression.
+func (o *encryptOptions) unframe(data []byte, now time.Time) ([]byte, error) {
+	if len(data) < frameHeader || data[0] != frameVersion {
+		return nil, errBadFrame
+	}
+	flags := data[1]
+	if flags&^frameFlags != 0 {
+		return nil, errBadFrame
+	}
+	payload := data[frameHeader:]
+
+	if flags&framePadded != 0 {
+		i := len(payload) - 1
+		for i >= 0 && payload[i] == 0 {
+			i--
+		}
+		if i < 0 || payload[i] != 0x80 {
+			return nil, errBadFrame
+		}
+		payload = payload[:i]
+	}
+
+	if flags&frameExpires != 0 {
+		if len(payload) < frameExpiry {
+			return nil, errBadFrame
+		}
+		expires := time.Unix(int64(binary.BigEndian.Uint64(payload)), 0) // #nosec G115 -- written by frame from a Unix time
+		if !now.Before(expires) {
+			return nil, fmt.Errorf("%w: at %s", ErrExpired, expires.UTC().Format(time.RFC3339))
+		}
+		payload = payload[frameExpiry:]
+	}
+
+	if flags&frameCompressed != 0 {
+		if !o.compress {
+			return nil, errBadFrame
+		}
+		limit := int64(len(payload)) * maxInflateRatio
+		decompressed, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(payload)), limit+1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errBadFrame, err)
+		}
+		if int64(len(decompressed)) > limit {
+			zeroize(decompressed)
+			return nil, fmt.Errorf("%w: payload expands more than %dx", errBadFrame, maxInflateRatio)
+		}
+		payload = decompressed
+	}
+
+	return payload, nil
+}
+
+// deflate compresses data, reporting false if the result is not smaller or
+// would expand by more than maxInflateRatio.
+func deflate(data []byte) ([]byte, bool) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return nil, false
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, false
+	}
+	if err := w.Close(); err != nil {
+		return nil, false
+	}
+	if buf.Len() >= len(data) || len(data) > buf.Len()*maxInflateRatio {
+		return nil, false
+	}
+	return buf.Bytes(), true
+}
+
+// optionsEncryptor applies encryptOptions around another encryptor.
+type optionsEncryptor struct {
+	inner Encryptor
+	opts  *encryptOptions
+}
+
+// Padded returns an encryptor that pads plaintext before encrypting with enc
+// and removes the padding after decrypting.
+//
+// Padding is sealed inside enc's authenticated plaintext, so ciphertext
+// length reveals only the padded bucket. Ciphertext written by enc alone
+// cannot be decrypted by the padded encryptor, and vice versa.
+func Padded(enc Encryptor, pad Padding) Encryptor {
+	return &optionsEncryptor{inner: enc, opts: &encryptOptions{pad: pad}}
+}
+
+func (e *optionsEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	framed := e.opts.frame(plaintext, time.Now())
+	ciphertext, err := e.inner.Encrypt(framed)
+	if !overlaps(framed, ciphertext) {
+		zeroize(framed)
+	}
+	return ciphertext, err
+}
+
+func (e *optionsEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	data, err := e.inner.Decrypt(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return e.opts.unframe(data, time.Now())
+}
+
+func (e *optionsEncryptor) EncryptContext(ctx context.Context, plaintext []byte) ([]byte, error) {
+	framed := e.opts.frame(plaintext, time.Now())
+	ciphertext, err := encryptContext(ctx, e.inner, framed)
+	if !overlaps(framed, ciphertext) {
+		zeroize(framed)
+	}
+	return ciphertext, err
+}
+
+func (e *optionsEncryptor) DecryptContext(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	data, err := decryptContext(ctx, e.inner, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return e.opts.unframe(data, time.Now())
+}
+
+// Destroy destroys the wrapped encryptor if it implements Destroyer.
+func (e *optionsEncryptor) Destroy() {
+	if d, ok := e.inner.(Destroyer); ok {
+		d.Destroy()
+	}
+}
+
+// CanEncrypt reports whether the wrapped encryptor can encrypt.
+func (e *optionsEncryptor) CanEncrypt() bool {
+	kc, ok := e.inner.(KeyCapabilities)
+	return !ok || kc.CanEncrypt()
+}
+
+// CanDecrypt reports whether the wrapped encryptor can decrypt.
+func (e *optionsEncryptor) CanDecrypt() bool {
+	kc, ok := e.inner.(KeyCapabilities)
+	return !ok || kc.CanDecrypt()
+}
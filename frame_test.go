@@ -0,0 +1,387 @@
+package cereal
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPadding_Buckets(t *testing.T) {
+	tests := []struct {
+		name string
+		pad  Padding
+		n    int
+		want int
+	}{
+		{"pow2 empty", PadPowerOfTwo(), 0, 16},
+		{"pow2 minimum", PadPowerOfTwo(), 15, 16},
+		{"pow2 next", PadPowerOfTwo(), 16, 32},
+		{"pow2 large", PadPowerOfTwo(), 100, 128},
+		{"block exact", PadBlock(32), 31, 32},
+		{"block next", PadBlock(32), 32, 64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pad.padTo(tt.n); got != tt.want {
+				t.Errorf("padTo(%d) = %d, want %d", tt.n, got, tt.want)
+			}
+		})
+	}
+
+	if PadBlock(0).enabled() {
+		t.Error("PadBlock(0) should disable padding")
+	}
+}
+
+func TestFrame_RoundTrip(t *testing.T) {
+	for _, opts := range []*encryptOptions{
+		{},
+		{pad: PadPowerOfTwo()},
+		{pad: PadBlock(64)},
+	} {
+		for _, pt := range []string{"", "a", "trailing\x00zeros\x00\x00", strings.Repeat("x", 200)} {
+			framed := opts.frame([]byte(pt), time.Now())
+			got, err := opts.unframe(framed, time.Now())
+			if err != nil {
+				t.Fatalf("unframe(%q) error: %v", pt, err)
+			}
+			if string(got) != pt {
+				t.Errorf("unframe() = %q, want %q", got, pt)
+			}
+		}
+	}
+}
+
+func TestFrame_Malformed(t *testing.T) {
+	for _, data := range [][]byte{
+		nil,
+		{frameVersion},
+		{9, 0},
+		{frameVersion, framePadded, 'a', 0, 0},
+		{frameVersion, 0x40},
+	} {
+		if _, err := (&encryptOptions{}).unframe(data, time.Now()); !errors.Is(err, errBadFrame) {
+			t.Errorf("unframe(%v) expected errBadFrame, got %v", data, err)
+		}
+	}
+}
+
+func TestParseEncryptTag(t *testing.T) {
+	algo, opts, err := parseEncryptTag("aes")
+	if err != nil || algo != "aes" || opts != nil {
+		t.Errorf("parseEncryptTag(aes) = %q, %v, %v", algo, opts, err)
+	}
+
+	algo, opts, err = parseEncryptTag("aes,pad=pow2")
+	if err != nil || algo != "aes" || !opts.pad.pow2 {
+		t.Errorf("parseEncryptTag(aes,pad=pow2) = %q, %+v, %v", algo, opts, err)
+	}
+
+	_, opts, err = parseEncryptTag("aes,pad=64")
+	if err != nil || opts.pad.block != 64 {
+		t.Errorf("parseEncryptTag(aes,pad=64) = %+v, %v", opts, err)
+	}
+
+	for _, bad := range []string{"aes,pad=0", "aes,pad=big", "aes,zip"} {
+		if _, _, err := parseEncryptTag(bad); err == nil {
+			t.Errorf("parseEncryptTag(%q) expected error", bad)
+		}
+	}
+}
+
+// PaddedUser pads Name to power-of-two buckets.
+type PaddedUser struct {
+	Name string `json:"name" store.encrypt:"aes,pad=pow2" load.decrypt:"aes"`
+	Note string `json:"note" store.encrypt:"aes,pad=64" load.decrypt:"aes,pad=64"`
+}
+
+func (u PaddedUser) Clone() PaddedUser { return u }
+
+func TestProcessor_PaddedFields(t *testing.T) {
+	enc, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	proc, err := NewProcessor[PaddedUser]()
+	if err != nil {
+		t.Fatalf("NewProcessor() error: %v", err)
+	}
+	proc.SetEncryptor(EncryptAES, enc)
+
+	ctx := context.Background()
+	short, err := proc.Store(ctx, PaddedUser{Name: "Al", Note: ""})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	long, err := proc.Store(ctx, PaddedUser{Name: "Bartholomew", Note: "a longer note"})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	if len(short.Name) != len(long.Name) || len(short.Note) != len(long.Note) {
+		t.Errorf("padded ciphertexts differ in length: %d/%d, %d/%d",
+			len(short.Name), len(long.Name), len(short.Note), len(long.Note))
+	}
+
+	// load.decrypt inherits options from store.encrypt when it has none.
+	loaded, err := proc.Load(ctx, long)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if loaded.Name != "Bartholomew" || loaded.Note != "a longer note" {
+		t.Errorf("round trip mismatch: %+v", loaded)
+	}
+}
+
+type badOptionUser struct {
+	Name string `store.encrypt:"aes,pad=nope"`
+}
+
+func (u badOptionUser) Clone() badOptionUser { return u }
+
+func TestProcessor_InvalidEncryptOption(t *testing.T) {
+	if _, err := NewProcessor[badOptionUser](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expected ErrInvalidTag, got %v", err)
+	}
+}
+
+func TestPadded(t *testing.T) {
+	aes, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	enc := Padded(aes, PadBlock(32))
+
+	a, err := enc.Encrypt([]byte("x"))
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+	b, _ := enc.Encrypt([]byte(strings.Repeat("y", 29)))
+	if len(a) != len(b) {
+		t.Errorf("ciphertext lengths differ: %d vs %d", len(a), len(b))
+	}
+
+	pt, err := enc.Decrypt(a)
+	if err != nil || string(pt) != "x" {
+		t.Errorf("Decrypt() = %q, %v", pt, err)
+	}
+
+	// Ciphertext from the unpadded encryptor is rejected.
+	raw, _ := aes.Encrypt([]byte("x"))
+	if _, err := enc.Decrypt(raw); !errors.Is(err, errBadFrame) {
+		t.Errorf("expected errBadFrame, got %v", err)
+	}
+}
+
+func TestPadded_KeyCapabilities(t *testing.T) {
+	priv, _ := rsa.GenerateKey(rand.Reader, 2048)
+	enc := Padded(RSA(&priv.PublicKey, nil), PadPowerOfTwo()).(KeyCapabilities)
+	if !enc.CanEncrypt() || enc.CanDecrypt() {
+		t.Errorf("capabilities = %v/%v, want true/false", enc.CanEncrypt(), enc.CanDecrypt())
+	}
+}
+
+func TestFrame_TTL(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	opts := &encryptOptions{ttl: time.Hour, pad: PadPowerOfTwo()}
+
+	framed := opts.frame([]byte("token"), start)
+
+	got, err := opts.unframe(framed, start.Add(59*time.Minute))
+	if err != nil || string(got) != "token" {
+		t.Errorf("unframe() before expiry = %q, %v", got, err)
+	}
+
+	if _, err := opts.unframe(framed, start.Add(time.Hour)); !errors.Is(err, ErrExpired) {
+		t.Errorf("expected ErrExpired at expiry, got %v", err)
+	}
+}
+
+func TestFrame_TTLRoundsUp(t *testing.T) {
+	// Expiry is stored in whole seconds; a sub-second remainder must not
+	// shorten the TTL.
+	start := time.Date(2025, 1, 1, 12, 0, 0, 600_000_000, time.UTC)
+	for _, ttl := range []time.Duration{900 * time.Millisecond, time.Second} {
+		opts := &encryptOptions{ttl: ttl}
+		framed := opts.frame([]byte("token"), start)
+		if _, err := opts.unframe(framed, start.Add(ttl-time.Nanosecond)); err != nil {
+			t.Errorf("ttl=%s: unframe() before expiry error: %v", ttl, err)
+		}
+		if _, err := opts.unframe(framed, start.Add(ttl+time.Second)); !errors.Is(err, ErrExpired) {
+			t.Errorf("ttl=%s: expected ErrExpired a second after expiry, got %v", ttl, err)
+		}
+	}
+}
+
+// TokenRecord holds a reset token that expires a day after it is stored.
+type TokenRecord struct {
+	Token string `json:"token" store.encrypt:"aes,ttl=24h" load.decrypt:"aes"`
+}
+
+func (r TokenRecord) Clone() TokenRecord { return r }
+
+func TestProcessor_EncryptTTL(t *testing.T) {
+	enc, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	proc, err := NewProcessor[TokenRecord]()
+	if err != nil {
+		t.Fatalf("NewProcessor() error: %v", err)
+	}
+	proc.SetEncryptor(EncryptAES, enc)
+	proc.SetClock(func() time.Time { return now })
+
+	ctx := context.Background()
+	stored, err := proc.Store(ctx, TokenRecord{Token: "reset-123"})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+
+	now = now.Add(23 * time.Hour)
+	loaded, err := proc.Load(ctx, stored)
+	if err != nil {
+		t.Fatalf("Load() before expiry error: %v", err)
+	}
+	if loaded.Token != "reset-123" {
+		t.Errorf("Token = %q, want %q", loaded.Token, "reset-123")
+	}
+
+	now = now.Add(time.Hour)
+	_, err = proc.Load(ctx, stored)
+	if !errors.Is(err, ErrExpired) {
+		t.Fatalf("expected ErrExpired, got %v", err)
+	}
+	if errors.Is(err, ErrDecrypt) {
+		t.Error("expired data should not report ErrDecrypt")
+	}
+}
+
+func TestProcessor_SetClockNil(t *testing.T) {
+	enc, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	proc, _ := NewProcessor[TokenRecord]()
+	proc.SetEncryptor(EncryptAES, enc).SetClock(nil)
+
+	ctx := context.Background()
+	stored, err := proc.Store(ctx, TokenRecord{Token: "reset-123"})
+	if err != nil {
+		t.Fatalf("Store() with nil clock error: %v", err)
+	}
+	if _, err := proc.Load(ctx, stored); err != nil {
+		t.Errorf("Load() with nil clock error: %v", err)
+	}
+}
+
+func TestParseEncryptTag_TTL(t *testing.T) {
+	_, opts, err := parseEncryptTag("aes,ttl=15m,pad=pow2")
+	if err != nil || opts.ttl != 15*time.Minute || !opts.pad.pow2 {
+		t.Errorf("parseEncryptTag() = %+v, %v", opts, err)
+	}
+	for _, bad := range []string{"aes,ttl=0s", "aes,ttl=-1h", "aes,ttl=soon"} {
+		if _, _, err := parseEncryptTag(bad); err == nil {
+			t.Errorf("parseEncryptTag(%q) expected error", bad)
+		}
+	}
+}
+
+func TestFrame_Compress(t *testing.T) {
+	opts := &encryptOptions{compress: true, compressMin: 64}
+	doc := []byte(strings.Repeat(`{"field":"value"},`, 100))
+
+	framed := opts.frame(doc, time.Now())
+	if framed[1]&frameCompressed == 0 {
+		t.Fatal("expected large repetitive payload to be compressed")
+	}
+	if len(framed) >= len(doc) {
+		t.Errorf("framed size %d not smaller than %d", len(framed), len(doc))
+	}
+	got, err := opts.unframe(framed, time.Now())
+	if err != nil || string(got) != string(doc) {
+		t.Errorf("unframe() round trip failed: %v", err)
+	}
+
+	small := opts.frame([]byte("short"), time.Now())
+	if small[1]&frameCompressed != 0 {
+		t.Error("payload below threshold should not be compressed")
+	}
+
+	random := make([]byte, 256)
+	_, _ = rand.Read(random)
+	if framed := opts.frame(random, time.Now()); framed[1]&frameCompressed != 0 {
+		t.Error("incompressible payload should be stored uncompressed")
+	}
+}
+
+func TestFrame_DecompressionBomb(t *testing.T) {
+	opts := &encryptOptions{compress: true, compressMin: 64}
+
+	// Zeros deflate about 1000:1; frame stores them uncompressed instead.
+	zeros := make([]byte, 1<<20)
+	if framed := opts.frame(zeros, time.Now()); framed[1]&frameCompressed != 0 {
+		t.Error("payload expanding beyond maxInflateRatio should be stored uncompressed")
+	}
+
+	// A frame crafted by another writer is rejected, not inflated.
+	var buf bytes.Buffer
+	w, _ := flate.NewWriter(&buf, flate.BestCompression)
+	_, _ = w.Write(zeros)
+	_ = w.Close()
+	bomb := append([]byte{frameVersion, frameCompressed}, buf.Bytes()...)
+	if _, err := opts.unframe(bomb, time.Now()); !errors.Is(err, errBadFrame) {
+		t.Errorf("unframe(bomb) = %v, want errBadFrame", err)
+	}
+
+	// Fields without the compress option never decompress.
+	doc := opts.frame([]byte(strings.Repeat(`{"field":"value"},`, 100)), time.Now())
+	if _, err := (&encryptOptions{pad: PadPowerOfTwo()}).unframe(doc, time.Now()); !errors.Is(err, errBadFrame) {
+		t.Errorf("unframe(compressed) without compress = %v, want errBadFrame", err)
+	}
+}
+
+func TestParseEncryptTag_Compress(t *testing.T) {
+	_, opts, err := parseEncryptTag("envelope,compress")
+	if err != nil || !opts.compress || opts.compressMin != defaultCompressMin {
+		t.Errorf("parseEncryptTag(compress) = %+v, %v", opts, err)
+	}
+	_, opts, err = parseEncryptTag("envelope,compress=0")
+	if err != nil || !opts.compress || opts.compressMin != 0 {
+		t.Errorf("parseEncryptTag(compress=0) = %+v, %v", opts, err)
+	}
+	if _, _, err := parseEncryptTag("envelope,compress=big"); err == nil {
+		t.Error("expected error for invalid threshold")
+	}
+}
+
+// DocumentRecord compresses its body before envelope encryption.
+type DocumentRecord struct {
+	Body []byte `json:"body" store.encrypt:"envelope,compress" load.decrypt:"envelope"`
+}
+
+func (r DocumentRecord) Clone() DocumentRecord {
+	return DocumentRecord{Body: append([]byte(nil), r.Body...)}
+}
+
+func TestProcessor_CompressedField(t *testing.T) {
+	enc, _ := Envelope([]byte("32-byte-key-for-aes-256-encrypt!"))
+	proc, err := NewProcessor[DocumentRecord]()
+	if err != nil {
+		t.Fatalf("NewProcessor() error: %v", err)
+	}
+	proc.SetEncryptor(EncryptEnvelope, enc)
+
+	body := []byte(strings.Repeat("lorem ipsum dolor sit amet ", 200))
+	ctx := context.Background()
+	stored, err := proc.Store(ctx, DocumentRecord{Body: body})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	if len(stored.Body) >= len(body) {
+		t.Errorf("stored size %d not smaller than plaintext %d", len(stored.Body), len(body))
+	}
+
+	loaded, err := proc.Load(ctx, stored)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if string(loaded.Body) != string(body) {
+		t.Error("round trip mismatch")
+	}
+}
@@ -1,6 +1,7 @@
 package cereal
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"crypto/sha512"
@@ -21,6 +22,22 @@ type Hasher interface {
 	Hash(plaintext []byte) (string, error)
 }
 
+// HasherContext is implemented by hashers that honor the operation's context.
+// The Processor prefers HashContext over Hash when available and passes
+// through the ctx given to Receive or Decode.
+type HasherContext interface {
+	// HashContext returns the hash of plaintext as a string.
+	HashContext(ctx context.Context, plaintext []byte) (string, error)
+}
+
+// hashContext hashes with h, preferring HasherContext.
+func hashContext(ctx context.Context, h Hasher, plaintext []byte) (string, error) {
+	if hc, ok := h.(HasherContext); ok {
+		return hc.HashContext(ctx, plaintext)
+	}
+	return h.Hash(plaintext)
+}
+
 // Argon2Params configures Argon2id hashing.
 type Argon2Params struct {
 	Time    uint32 // Number of iterations
@@ -0,0 +1,214 @@
+package cereal
+
+import (
+	"crypto/ecdh"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// jsonWebKey holds the JSON Web Key members (RFC 7517, 7518, 8037) cereal reads.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+
+	K string `json:"k"` // oct
+
+	N string `json:"n"` // RSA
+	E string `json:"e"`
+	P string `json:"p"`
+	Q string `json:"q"`
+
+	X string `json:"x"` // OKP
+	D string `json:"d"` // RSA or OKP private exponent/key
+}
+
+// FromJWK returns an encryptor for a single JSON Web Key.
+//
+// Supported key types:
+//   - oct: AES-GCM, or XChaCha20-Poly1305 when alg is "XC20P"
+//   - RSA: hybrid RSA-OAEP; private keys need the p and q members
+//   - OKP with crv "X25519": X25519 ECIES
+//
+// RSA and OKP keys without private members give encrypt-only encryptors.
+// Keys marked "use": "sig" are rejected. All errors wrap ErrInvalidKey.
+func FromJWK(data []byte) (Encryptor, error) {
+	var jwk jsonWebKey
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return nil, fmt.Errorf("%w: jwk: %w", ErrInvalidKey, err)
+	}
+	return jwk.encryptor()
+}
+
+// FromJWKSet returns a Keyring holding every key of a JWK Set under its kid.
+//
+// Each key must have a kid, unique within the set. The first key becomes
+// the primary; use Keyring.SetPrimary to choose another. All errors wrap
+// ErrInvalidKey and name the offending key.
+func FromJWKSet(data []byte) (*Keyring, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("%w: jwk set: %w", ErrInvalidKey, err)
+	}
+	if len(set.Keys) == 0 {
+		return nil, fmt.Errorf("%w: jwk set: no keys", ErrInvalidKey)
+	}
+
+	ring := &Keyring{keys: make(map[string]Encryptor, len(set.Keys))}
+	for i, jwk := range set.Keys {
+		if jwk.Kid == "" {
+			return nil, fmt.Errorf("%w: jwk set: key %d has no kid", ErrInvalidKey, i)
+		}
+		if _, dup := ring.keys[jwk.Kid]; dup {
+			return nil, fmt.Errorf("%w: jwk set: duplicate kid %q", ErrInvalidKey, jwk.Kid)
+		}
+
+		enc, err := jwk.encryptor()
+		if err != nil {
+			return nil, fmt.Errorf("jwk set: key %q: %w", jwk.Kid, err)
+		}
+		if err := ring.Add(jwk.Kid, enc); err != nil {
+			return nil, fmt.Errorf("jwk set: key %d: %w", i, err)
+		}
+	}
+
+	if err := ring.SetPrimary(set.Keys[0].Kid); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
+
+// encryptor builds the encryptor for the key.
+func (k *jsonWebKey) encryptor() (Encryptor, error) {
+	if k.Use != "" && k.Use != "enc" {
+		return nil, fmt.Errorf("%w: jwk: use %q is not encryption", ErrInvalidKey, k.Use)
+	}
+
+	switch k.Kty {
+	case "oct":
+		return k.octEncryptor()
+	case "RSA":
+		return k.rsaEncryptor()
+	case "OKP":
+		return k.okpEncryptor()
+	default:
+		return nil, fmt.Errorf("%w: jwk: unsupported kty %q", ErrInvalidKey, k.Kty)
+	}
+}
+
+func (k *jsonWebKey) octEncryptor() (Encryptor, error) {
+	key, err := jwkBytes("k", k.K)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroize(key)
+
+	switch k.Alg {
+	case "", "A128GCM", "A192GCM", "A256GCM":
+		return AES(key)
+	case "XC20P":
+		return XChaCha(key)
+	default:
+		return nil, fmt.Errorf("%w: jwk: unsupported oct alg %q", ErrInvalidKey, k.Alg)
+	}
+}
+
+func (k *jsonWebKey) rsaEncryptor() (Encryptor, error) {
+	n, err := jwkInt("n", k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := jwkInt("e", k.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > math.MaxInt32 {
+		return nil, fmt.Errorf("%w: jwk: invalid RSA exponent", ErrInvalidKey)
+	}
+	pub := &rsa.PublicKey{N: n, E: int(e.Int64())}
+
+	if k.D == "" {
+		return RSA(pub, nil), nil
+	}
+
+	d, err := jwkInt("d", k.D)
+	if err != nil {
+		return nil, err
+	}
+	p, err := jwkInt("p", k.P)
+	if err != nil {
+		return nil, err
+	}
+	q, err := jwkInt("q", k.Q)
+	if err != nil {
+		return nil, err
+	}
+
+	priv := &rsa.PrivateKey{PublicKey: *pub, D: d, Primes: []*big.Int{p, q}}
+	if err := priv.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: jwk: %w", ErrInvalidKey, err)
+	}
+	priv.Precompute()
+	return RSA(&priv.PublicKey, priv), nil
+}
+
+func (k *jsonWebKey) okpEncryptor() (Encryptor, error) {
+	if k.Crv != "X25519" {
+		return nil, fmt.Errorf("%w: jwk: unsupported OKP curve %q", ErrInvalidKey, k.Crv)
+	}
+
+	x, err := jwkBytes("x", k.X)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := ecdh.X25519().NewPublicKey(x)
+	if err != nil {
+		return nil, fmt.Errorf("%w: jwk: %w", ErrInvalidKey, err)
+	}
+
+	if k.D == "" {
+		return X25519(pub, nil)
+	}
+
+	d, err := jwkBytes("d", k.D)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroize(d)
+	priv, err := ecdh.X25519().NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("%w: jwk: %w", ErrInvalidKey, err)
+	}
+	return X25519(pub, priv)
+}
+
+// jwkBytes decodes a required base64url member. Padding is tolerated.
+func jwkBytes(name, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("%w: jwk: missing %q", ErrInvalidKey, name)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, fmt.Errorf("%w: jwk: %q is not base64url: %w", ErrInvalidKey, name, err)
+	}
+	return b, nil
+}
+
+// jwkInt decodes a required base64url big-endian integer member.
+func jwkInt(name, value string) (*big.Int, error) {
+	b, err := jwkBytes(name, value)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroize(b)
+	return new(big.Int).SetBytes(b), nil
+}
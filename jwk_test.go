@@ -0,0 +1,162 @@
+package cereal
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func b64url(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func rsaJWK(kid string, priv *rsa.PrivateKey, private bool) string {
+	members := fmt.Sprintf(`"kty":"RSA","kid":%q,"n":%q,"e":%q`,
+		kid, b64url(priv.N.Bytes()), b64url(big.NewInt(int64(priv.E)).Bytes()))
+	if private {
+		members += fmt.Sprintf(`,"d":%q,"p":%q,"q":%q`,
+			b64url(priv.D.Bytes()), b64url(priv.Primes[0].Bytes()), b64url(priv.Primes[1].Bytes()))
+	}
+	return "{" + members + "}"
+}
+
+func TestFromJWK_Oct(t *testing.T) {
+	key := []byte("32-byte-key-for-aes-256-encrypt!")
+
+	aes, err := FromJWK([]byte(`{"kty":"oct","alg":"A256GCM","k":"` + b64url(key) + `"}`))
+	if err != nil {
+		t.Fatalf("FromJWK(oct) error: %v", err)
+	}
+	reference, _ := AES(key)
+	ct, _ := aes.Encrypt([]byte("secret"))
+	if pt, err := reference.Decrypt(ct); err != nil || string(pt) != "secret" {
+		t.Errorf("AES Decrypt() = %q, %v", pt, err)
+	}
+
+	xchacha, err := FromJWK([]byte(`{"kty":"oct","alg":"XC20P","k":"` + b64url(key) + `"}`))
+	if err != nil {
+		t.Fatalf("FromJWK(XC20P) error: %v", err)
+	}
+	reference, _ = XChaCha(key)
+	ct, _ = xchacha.Encrypt([]byte("secret"))
+	if pt, err := reference.Decrypt(ct); err != nil || string(pt) != "secret" {
+		t.Errorf("XChaCha Decrypt() = %q, %v", pt, err)
+	}
+}
+
+func TestFromJWK_RSA(t *testing.T) {
+	priv, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	writer, err := FromJWK([]byte(rsaJWK("a", priv, false)))
+	if err != nil {
+		t.Fatalf("FromJWK(public) error: %v", err)
+	}
+	if writer.(KeyCapabilities).CanDecrypt() {
+		t.Error("public JWK should be encrypt-only")
+	}
+
+	reader, err := FromJWK([]byte(rsaJWK("a", priv, true)))
+	if err != nil {
+		t.Fatalf("FromJWK(private) error: %v", err)
+	}
+
+	ct, _ := writer.Encrypt([]byte("secret"))
+	if pt, err := reader.Decrypt(ct); err != nil || string(pt) != "secret" {
+		t.Errorf("Decrypt() = %q, %v", pt, err)
+	}
+}
+
+func TestFromJWK_OKP(t *testing.T) {
+	priv, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	x := b64url(priv.PublicKey().Bytes())
+
+	writer, err := FromJWK([]byte(`{"kty":"OKP","crv":"X25519","x":"` + x + `"}`))
+	if err != nil {
+		t.Fatalf("FromJWK(public) error: %v", err)
+	}
+	reader, err := FromJWK([]byte(`{"kty":"OKP","crv":"X25519","x":"` + x + `","d":"` + b64url(priv.Bytes()) + `"}`))
+	if err != nil {
+		t.Fatalf("FromJWK(private) error: %v", err)
+	}
+
+	ct, _ := writer.Encrypt([]byte("secret"))
+	if pt, err := reader.Decrypt(ct); err != nil || string(pt) != "secret" {
+		t.Errorf("Decrypt() = %q, %v", pt, err)
+	}
+}
+
+func TestFromJWK_Invalid(t *testing.T) {
+	other, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	priv, _ := ecdh.X25519().GenerateKey(rand.Reader)
+
+	tests := map[string]string{
+		"not json":     `{`,
+		"unknown kty":  `{"kty":"EC"}`,
+		"signing key":  `{"kty":"oct","use":"sig","k":"` + b64url(make([]byte, 32)) + `"}`,
+		"missing k":    `{"kty":"oct"}`,
+		"bad k":        `{"kty":"oct","k":"!!!"}`,
+		"short k":      `{"kty":"oct","k":"` + b64url(make([]byte, 5)) + `"}`,
+		"unknown alg":  `{"kty":"oct","alg":"HS256","k":"` + b64url(make([]byte, 32)) + `"}`,
+		"ed25519":      `{"kty":"OKP","crv":"Ed25519","x":"` + b64url(make([]byte, 32)) + `"}`,
+		"rsa exponent": `{"kty":"RSA","n":"AQAB","e":"AQ"}`,
+		"okp mismatch": `{"kty":"OKP","crv":"X25519","x":"` + b64url(other.PublicKey().Bytes()) +
+			`","d":"` + b64url(priv.Bytes()) + `"}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := FromJWK([]byte(data)); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("expected ErrInvalidKey, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFromJWKSet(t *testing.T) {
+	priv, _ := rsa.GenerateKey(rand.Reader, 2048)
+	set := `{"keys":[` +
+		`{"kty":"oct","kid":"2025","k":"` + b64url([]byte("32-byte-key-for-aes-256-encrypt!")) + `"},` +
+		rsaJWK("legacy", priv, true) + `]}`
+
+	ring, err := FromJWKSet([]byte(set))
+	if err != nil {
+		t.Fatalf("FromJWKSet() error: %v", err)
+	}
+	if ring.Primary() != "2025" {
+		t.Errorf("Primary() = %q, want 2025", ring.Primary())
+	}
+
+	legacy := RSA(&priv.PublicKey, nil)
+	old, _ := legacy.Encrypt([]byte("old"))
+	old = append([]byte{6, 'l', 'e', 'g', 'a', 'c', 'y'}, old...)
+	if pt, err := ring.Decrypt(old); err != nil || string(pt) != "old" {
+		t.Errorf("Decrypt(legacy) = %q, %v", pt, err)
+	}
+
+	ct, _ := ring.Encrypt([]byte("new"))
+	if pt, err := ring.Decrypt(ct); err != nil || string(pt) != "new" {
+		t.Errorf("Decrypt() = %q, %v", pt, err)
+	}
+}
+
+func TestFromJWKSet_Invalid(t *testing.T) {
+	k := `"k":"` + b64url(make([]byte, 32)) + `"`
+	tests := map[string]string{
+		"not json":      `[]`,
+		"empty":         `{"keys":[]}`,
+		"missing kid":   `{"keys":[{"kty":"oct",` + k + `}]}`,
+		"duplicate kid": `{"keys":[{"kty":"oct","kid":"a",` + k + `},{"kty":"oct","kid":"a",` + k + `}]}`,
+		"bad key":       `{"keys":[{"kty":"oct","kid":"a","k":"AA"}]}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := FromJWKSet([]byte(data)); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("expected ErrInvalidKey, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package cereal
+
+import (
+	"container/list"
+	"crypto/cipher"
+	"crypto/sha256"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// defaultDataKeyCacheEntries bounds the decrypt cache when MaxEntries is unset.
+const defaultDataKeyCacheEntries = 1024
+
+// DataKeyCacheConfig configures data-key caching for envelope encryption.
+//
+// When encrypting, one data key (and its wrapped form) is reused until it has
+// protected MaxMessages messages or is older than MaxAge. When decrypting,
+// unwrapped data keys are cached by a digest of their wrapped form so repeat
+// reads skip the KeyWrapper.
+//
+// Caching trades per-message key isolation for fewer KeyWrapper calls: every
+// message encrypted under a cached key is exposed if that key leaks. Expired
+// and evicted keys are zeroized.
+type DataKeyCacheConfig struct {
+	// MaxMessages limits how many messages one data key encrypts.
+	// Zero means no message limit (MaxAge still applies).
+	MaxMessages int
+
+	// MaxAge limits how long a data key is cached, for both encryption and
+	// decryption. Zero means no age limit (MaxMessages still applies).
+	MaxAge time.Duration
+
+	// MaxEntries bounds the number of unwrapped data keys cached for decryption.
+	// Least recently used keys are evicted first. Defaults to 1024.
+	MaxEntries int
+}
+
+// enabled reports whether the configuration turns caching on.
+func (c DataKeyCacheConfig) enabled() bool {
+	return c.MaxMessages > 0 || c.MaxAge > 0
+}
+
+// cachedDataKey is a data key with its prepared AEAD.
+type cachedDataKey struct {
+	digest  [sha256.Size]byte // digest of the wrapped key (decrypt cache index)
+	key     []byte            // plaintext data key, zeroized on eviction
+	wrapped []byte            // wrapped data key (encrypt side only)
+	aead    cipher.AEAD
+	uses    int
+	expires time.Time // zero means no expiry
+}
+
+// dataKeyCache holds the current encryption data key and a bounded LRU of
+// unwrapped decryption data keys. Safe for concurrent use.
+type dataKeyCache struct {
+	cfg DataKeyCacheConfig
+	now func() time.Time
+
+	mu      sync.Mutex
+	current *cachedDataKey
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List // front is most recently used
+	purged  bool
+}
+
+// newDataKeyCache creates a cache for the given configuration.
+func newDataKeyCache(cfg DataKeyCacheConfig) *dataKeyCache {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = defaultDataKeyCacheEntries
+	}
+	return &dataKeyCache{
+		cfg:     cfg,
+		now:     time.Now,
+		entries: make(map[[sha256.Size]byte]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// acquire returns the current encryption key and counts one use against it.
+// Returns nil if there is no usable key; the caller then installs a new one.
+func (c *dataKeyCache) acquire() *cachedDataKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dk := c.current
+	if dk == nil {
+		return nil
+	}
+
+	if c.expired(dk) || (c.cfg.MaxMessages > 0 && dk.uses >= c.cfg.MaxMessages) {
+		c.current = nil
+		// The key stays readable via the decrypt cache until it expires there.
+		if _, ok := c.entries[dk.digest]; !ok {
+			zeroize(dk.key)
+		}
+		return nil
+	}
+
+	dk.uses++
+	return dk
+}
+
+// install makes dk the current encryption key and also caches it for
+// decryption, so reading back recent writes needs no KeyWrapper call.
+func (c *dataKeyCache) install(dk *cachedDataKey) {
+	dk.uses = 1
+	dk.digest = sha256.Sum256(dk.wrapped)
+	if c.cfg.MaxAge > 0 {
+		dk.expires = c.now().Add(c.cfg.MaxAge)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.purged {
+		zeroize(dk.key)
+		return
+	}
+	c.current = dk
+	c.insert(dk)
+}
+
+// lookup returns the cached AEAD for a wrapped-key digest, or nil.
+func (c *dataKeyCache) lookup(digest [sha256.Size]byte) cipher.AEAD {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[digest]
+	if !ok {
+		return nil
+	}
+
+	dk, _ := elem.Value.(*cachedDataKey)
+	if c.expired(dk) {
+		c.remove(elem)
+		return nil
+	}
+
+	c.order.MoveToFront(elem)
+	return dk.aead
+}
+
+// store caches an unwrapped decryption key.
+func (c *dataKeyCache) store(dk *cachedDataKey) {
+	if c.cfg.MaxAge > 0 {
+		dk.expires = c.now().Add(c.cfg.MaxAge)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.insert(dk)
+}
+
+// insert adds dk to the decrypt cache, evicting the least recently used
+// entry when full. Caller must hold c.mu.
+func (c *dataKeyCache) insert(dk *cachedDataKey) {
+	if c.purged {
+		zeroize(dk.key)
+		return
+	}
+	if elem, ok := c.entries[dk.digest]; ok {
+		// Concurrent unwrap of the same key; keep the existing entry.
+		c.order.MoveToFront(elem)
+		if existing, _ := elem.Value.(*cachedDataKey); existing != dk {
+			zeroize(dk.key)
+		}
+		return
+	}
+
+	for c.order.Len() >= c.cfg.MaxEntries {
+		c.remove(c.order.Back())
+	}
+
+	c.entries[dk.digest] = c.order.PushFront(dk)
+}
+
+// remove evicts an entry and zeroizes its key unless it is still the
+// current encryption key. Caller must hold c.mu.
+func (c *dataKeyCache) remove(elem *list.Element) {
+	dk, _ := c.order.Remove(elem).(*cachedDataKey)
+	delete(c.entries, dk.digest)
+	if dk != c.current {
+		zeroize(dk.key)
+	}
+}
+
+// purge zeroizes and drops every cached key. Keys installed or stored
+// afterwards are zeroized instead of cached.
+func (c *dataKeyCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.purged = true
+	if c.current != nil {
+		zeroize(c.current.key)
+		c.current = nil
+	}
+	for c.order.Len() > 0 {
+		c.remove(c.order.Back())
+	}
+}
+
+// expired reports whether dk has passed its expiry. Caller must hold c.mu.
+func (c *dataKeyCache) expired(dk *cachedDataKey) bool {
+	return !dk.expires.IsZero() && !c.now().Before(dk.expires)
+}
+
+// zeroize overwrites key material in place.
+func zeroize(b []byte) {
+	clear(b)
+}
+
+// overlaps reports whether x and y share any memory, as when an encryptor
+// returns its input or transforms it in place.
+func overlaps(x, y []byte) bool {
+	return len(x) > 0 && len(y) > 0 &&
+		uintptr(unsafe.Pointer(&x[0])) <= uintptr(unsafe.Pointer(&y[len(y)-1])) && // #nosec G103 -- address comparison only
+		uintptr(unsafe.Pointer(&y[0])) <= uintptr(unsafe.Pointer(&x[len(x)-1])) // #nosec G103 -- address comparison only
+}
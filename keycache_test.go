@@ -0,0 +1,182 @@
+package cereal
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newCachedEnvelope returns an envelope encryptor with caching and a
+// recording wrapper, plus a controllable clock.
+func newCachedEnvelope(t *testing.T, cfg DataKeyCacheConfig) (*envelopeEncryptor, *recordingWrapper, *time.Time) {
+	t.Helper()
+
+	inner, _ := AESKeyWrapper([]byte("32-byte-master-key-for-envelope!"))
+	w := &recordingWrapper{KeyWrapper: inner}
+
+	enc, err := EnvelopeWithWrapper(w, EnvelopeConfig{Cache: cfg})
+	if err != nil {
+		t.Fatalf("EnvelopeWithWrapper() error: %v", err)
+	}
+
+	env, ok := enc.(*envelopeEncryptor)
+	if !ok || env.cache == nil {
+		t.Fatal("expected envelope encryptor with cache")
+	}
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	env.cache.now = func() time.Time { return now }
+	return env, w, &now
+}
+
+func TestDataKeyCache_Disabled(t *testing.T) {
+	enc, _ := Envelope([]byte("32-byte-master-key-for-envelope!"))
+	if env, ok := enc.(*envelopeEncryptor); !ok || env.cache != nil {
+		t.Error("zero DataKeyCacheConfig should disable caching")
+	}
+}
+
+func TestDataKeyCache_ReusesKeyForMaxMessages(t *testing.T) {
+	env, w, _ := newCachedEnvelope(t, DataKeyCacheConfig{MaxMessages: 3})
+
+	var ciphertexts [][]byte
+	for i := 0; i < 7; i++ {
+		c, err := env.Encrypt([]byte("hello"))
+		if err != nil {
+			t.Fatalf("Encrypt() error: %v", err)
+		}
+		ciphertexts = append(ciphertexts, c)
+	}
+
+	// 7 messages at 3 per key: 3 wraps
+	if w.wraps != 3 {
+		t.Errorf("wraps = %d, want 3", w.wraps)
+	}
+
+	// Messages under the same key still differ (random nonce)
+	if bytes.Equal(ciphertexts[0], ciphertexts[1]) {
+		t.Error("ciphertexts under a cached key should differ")
+	}
+
+	// Recently written keys are already in the decrypt cache
+	for _, c := range ciphertexts {
+		plaintext, err := env.Decrypt(c)
+		if err != nil {
+			t.Fatalf("Decrypt() error: %v", err)
+		}
+		if string(plaintext) != "hello" {
+			t.Errorf("Decrypt() = %q, want %q", plaintext, "hello")
+		}
+	}
+	if w.unwraps != 0 {
+		t.Errorf("unwraps = %d, want 0", w.unwraps)
+	}
+}
+
+func TestDataKeyCache_EncryptKeyExpires(t *testing.T) {
+	env, w, now := newCachedEnvelope(t, DataKeyCacheConfig{MaxAge: time.Minute})
+
+	_, _ = env.Encrypt([]byte("a"))
+	_, _ = env.Encrypt([]byte("b"))
+	if w.wraps != 1 {
+		t.Fatalf("wraps = %d, want 1", w.wraps)
+	}
+
+	*now = now.Add(time.Minute)
+	_, _ = env.Encrypt([]byte("c"))
+	if w.wraps != 2 {
+		t.Errorf("wraps = %d, want 2 after expiry", w.wraps)
+	}
+}
+
+func TestDataKeyCache_DecryptCachesUnwrappedKeys(t *testing.T) {
+	writer, _ := Envelope([]byte("32-byte-master-key-for-envelope!"))
+	ciphertext, _ := writer.Encrypt([]byte("hello"))
+
+	env, w, now := newCachedEnvelope(t, DataKeyCacheConfig{MaxAge: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		if _, err := env.Decrypt(ciphertext); err != nil {
+			t.Fatalf("Decrypt() error: %v", err)
+		}
+	}
+	if w.unwraps != 1 {
+		t.Errorf("unwraps = %d, want 1", w.unwraps)
+	}
+
+	*now = now.Add(2 * time.Minute)
+	if _, err := env.Decrypt(ciphertext); err != nil {
+		t.Fatalf("Decrypt() error: %v", err)
+	}
+	if w.unwraps != 2 {
+		t.Errorf("unwraps = %d, want 2 after expiry", w.unwraps)
+	}
+}
+
+func TestDataKeyCache_EvictsAndZeroizes(t *testing.T) {
+	writer, _ := Envelope([]byte("32-byte-master-key-for-envelope!"))
+	c1, _ := writer.Encrypt([]byte("one"))
+	c2, _ := writer.Encrypt([]byte("two"))
+
+	env, w, _ := newCachedEnvelope(t, DataKeyCacheConfig{MaxAge: time.Hour, MaxEntries: 1})
+
+	if _, err := env.Decrypt(c1); err != nil {
+		t.Fatalf("Decrypt() error: %v", err)
+	}
+
+	var first *cachedDataKey
+	for _, elem := range env.cache.entries {
+		first, _ = elem.Value.(*cachedDataKey)
+	}
+	if first == nil {
+		t.Fatal("expected cached entry")
+	}
+
+	// Second key evicts the first
+	if _, err := env.Decrypt(c2); err != nil {
+		t.Fatalf("Decrypt() error: %v", err)
+	}
+	if len(env.cache.entries) != 1 {
+		t.Errorf("entries = %d, want 1", len(env.cache.entries))
+	}
+	if !bytes.Equal(first.key, make([]byte, len(first.key))) {
+		t.Error("evicted data key should be zeroized")
+	}
+
+	// First key must be unwrapped again
+	if _, err := env.Decrypt(c1); err != nil {
+		t.Fatalf("Decrypt() error: %v", err)
+	}
+	if w.unwraps != 3 {
+		t.Errorf("unwraps = %d, want 3", w.unwraps)
+	}
+}
+
+func TestDataKeyCache_DestroyZeroizes(t *testing.T) {
+	env, _, _ := newCachedEnvelope(t, DataKeyCacheConfig{MaxMessages: 10})
+
+	ciphertext, err := env.Encrypt([]byte("hello"))
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+	current := env.cache.current
+	if current == nil {
+		t.Fatal("expected current data key")
+	}
+
+	env.Destroy()
+
+	if !bytes.Equal(current.key, make([]byte, len(current.key))) {
+		t.Error("cached data key should be zeroized")
+	}
+	if len(env.cache.entries) != 0 || env.cache.current != nil {
+		t.Error("cache should be empty after Destroy")
+	}
+	if _, err := env.Encrypt([]byte("hello")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Encrypt() after Destroy: expected ErrClosed, got %v", err)
+	}
+	if _, err := env.Decrypt(ciphertext); !errors.Is(err, ErrClosed) {
+		t.Errorf("Decrypt() after Destroy: expected ErrClosed, got %v", err)
+	}
+}
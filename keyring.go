@@ -0,0 +1,167 @@
+package cereal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// maxKeyIDLen is the longest key ID a Keyring accepts; it is stored in one byte.
+const maxKeyIDLen = 255
+
+// errUnknownKeyID indicates ciphertext names a key the Keyring does not hold.
+var errUnknownKeyID = errors.New("unknown key id")
+
+// Keyring is an Encryptor holding several keys by ID.
+//
+// It encrypts with the primary key and prefixes the ciphertext with that
+// key's ID. Decryption selects the key named in the ciphertext, so data
+// written under any key in the ring stays readable after the primary is
+// rotated.
+//
+// Format: [1 byte key ID length][key ID][ciphertext]
+type Keyring struct {
+	mu      sync.RWMutex
+	primary string
+	keys    map[string]Encryptor
+}
+
+// NewKeyring returns a Keyring with the given keys, encrypting with primary.
+func NewKeyring(primary string, keys map[string]Encryptor) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]Encryptor, len(keys))}
+	for kid, enc := range keys {
+		if err := k.Add(kid, enc); err != nil {
+			return nil, err
+		}
+	}
+	if err := k.SetPrimary(primary); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Add registers enc under kid, replacing any key with the same ID.
+// Key IDs must be 1 to 255 bytes. Safe for concurrent use.
+func (k *Keyring) Add(kid string, enc Encryptor) error {
+	if kid == "" || len(kid) > maxKeyIDLen {
+		return fmt.Errorf("%w: key id must be 1 to %d bytes", ErrInvalidKey, maxKeyIDLen)
+	}
+	if enc == nil {
+		return fmt.Errorf("%w: key %q has no encryptor", ErrInvalidKey, kid)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[kid] = enc
+	return nil
+}
+
+// SetPrimary selects the key used for encryption. Safe for concurrent use.
+func (k *Keyring) SetPrimary(kid string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[kid]; !ok {
+		return fmt.Errorf("%w: primary key %q not in keyring", ErrInvalidKey, kid)
+	}
+	k.primary = kid
+	return nil
+}
+
+// Primary returns the ID of the key used for encryption.
+func (k *Keyring) Primary() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.primary
+}
+
+// KeyIDs returns the IDs of all keys in the ring, sorted.
+func (k *Keyring) KeyIDs() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	ids := make([]string, 0, len(k.keys))
+	for kid := range k.keys {
+		ids = append(ids, kid)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func (k *Keyring) Encrypt(plaintext []byte) ([]byte, error) {
+	return k.EncryptContext(context.Background(), plaintext)
+}
+
+func (k *Keyring) Decrypt(ciphertext []byte) ([]byte, error) {
+	return k.DecryptContext(context.Background(), ciphertext)
+}
+
+// EncryptContext encrypts with the primary key, passing ctx through.
+func (k *Keyring) EncryptContext(ctx context.Context, plaintext []byte) ([]byte, error) {
+	k.mu.RLock()
+	kid, enc := k.primary, k.keys[k.primary]
+	k.mu.RUnlock()
+	if enc == nil {
+		return nil, ErrClosed
+	}
+
+	ciphertext, err := encryptContext(ctx, enc, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, 1+len(kid)+len(ciphertext))
+	out = append(out, byte(len(kid))) // #nosec G115 -- Add limits key IDs to 255 bytes
+	out = append(out, kid...)
+	return append(out, ciphertext...), nil
+}
+
+// DecryptContext decrypts with the key named in the ciphertext, passing ctx through.
+func (k *Keyring) DecryptContext(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 1 || len(ciphertext) < 1+int(ciphertext[0]) {
+		return nil, ErrCiphertextShort
+	}
+	kid := string(ciphertext[1 : 1+int(ciphertext[0])])
+
+	k.mu.RLock()
+	enc, ok := k.keys[kid]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %w %q", ErrDecrypt, errUnknownKeyID, kid)
+	}
+
+	return decryptContext(ctx, enc, ciphertext[1+len(kid):])
+}
+
+// CanEncrypt reports whether the primary key can encrypt.
+func (k *Keyring) CanEncrypt() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	enc := k.keys[k.primary]
+	kc, ok := enc.(KeyCapabilities)
+	return enc != nil && (!ok || kc.CanEncrypt())
+}
+
+// CanDecrypt reports whether any key in the ring can decrypt.
+func (k *Keyring) CanDecrypt() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, enc := range k.keys {
+		if kc, ok := enc.(KeyCapabilities); !ok || kc.CanDecrypt() {
+			return true
+		}
+	}
+	return false
+}
+
+// Destroy destroys every key that implements Destroyer and empties the ring.
+func (k *Keyring) Destroy() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, enc := range k.keys {
+		if d, ok := enc.(Destroyer); ok {
+			d.Destroy()
+		}
+	}
+	clear(k.keys)
+}
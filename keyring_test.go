@@ -0,0 +1,93 @@
+package cereal
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func newTestKeyring(t *testing.T) *Keyring {
+	t.Helper()
+	k1, _ := AES([]byte("key-one-for-aes-256-encryption!!"))
+	k2, _ := AES([]byte("key-two-for-aes-256-encryption!!"))
+	ring, err := NewKeyring("k1", map[string]Encryptor{"k1": k1, "k2": k2})
+	if err != nil {
+		t.Fatalf("NewKeyring() error: %v", err)
+	}
+	return ring
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	ring := newTestKeyring(t)
+
+	old, err := ring.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+	if string(old[1:1+old[0]]) != "k1" {
+		t.Errorf("ciphertext key id = %q, want k1", old[1:1+old[0]])
+	}
+
+	if err := ring.SetPrimary("k2"); err != nil {
+		t.Fatalf("SetPrimary() error: %v", err)
+	}
+	fresh, _ := ring.Encrypt([]byte("secret"))
+	if string(fresh[1:1+fresh[0]]) != "k2" {
+		t.Errorf("ciphertext key id = %q, want k2", fresh[1:1+fresh[0]])
+	}
+
+	for _, ct := range [][]byte{old, fresh} {
+		pt, err := ring.Decrypt(ct)
+		if err != nil {
+			t.Fatalf("Decrypt() error: %v", err)
+		}
+		if string(pt) != "secret" {
+			t.Errorf("Decrypt() = %q, want secret", pt)
+		}
+	}
+
+	if got := ring.KeyIDs(); !slices.Equal(got, []string{"k1", "k2"}) {
+		t.Errorf("KeyIDs() = %v", got)
+	}
+}
+
+func TestKeyring_Errors(t *testing.T) {
+	enc, _ := AES([]byte("key-one-for-aes-256-encryption!!"))
+
+	if _, err := NewKeyring("missing", map[string]Encryptor{"k1": enc}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("unknown primary: expected ErrInvalidKey, got %v", err)
+	}
+	if _, err := NewKeyring("", map[string]Encryptor{"": enc}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("empty key id: expected ErrInvalidKey, got %v", err)
+	}
+
+	ring := newTestKeyring(t)
+	ct, _ := ring.Encrypt([]byte("secret"))
+	other, _ := NewKeyring("k3", map[string]Encryptor{"k3": enc})
+	if _, err := other.Decrypt(ct); !errors.Is(err, ErrDecrypt) || !errors.Is(err, errUnknownKeyID) {
+		t.Errorf("unknown key id: expected ErrDecrypt, got %v", err)
+	}
+	if _, err := ring.Decrypt([]byte{5, 'k'}); !errors.Is(err, ErrCiphertextShort) {
+		t.Errorf("truncated: expected ErrCiphertextShort, got %v", err)
+	}
+}
+
+func TestKeyring_Capabilities(t *testing.T) {
+	priv, _ := rsa.GenerateKey(rand.Reader, 2048)
+	aes, _ := AES([]byte("key-one-for-aes-256-encryption!!"))
+
+	ring, _ := NewKeyring("pub", map[string]Encryptor{
+		"pub": RSA(&priv.PublicKey, nil),
+		"old": aes,
+	})
+	if !ring.CanEncrypt() || !ring.CanDecrypt() {
+		t.Error("keyring should encrypt with pub and decrypt with old")
+	}
+
+	ring, _ = NewKeyring("pub", map[string]Encryptor{"pub": RSA(nil, priv)})
+	if ring.CanEncrypt() {
+		t.Error("primary without a public key should not encrypt")
+	}
+}
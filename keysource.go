@@ -0,0 +1,243 @@
+package cereal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultReloadInterval is how often a KeyReloader polls when Interval is unset.
+const defaultReloadInterval = time.Minute
+
+// KeyMaterial is the key bytes read from a KeySource, by key ID.
+type KeyMaterial struct {
+	// Keys holds raw key bytes by key ID (version).
+	Keys map[string][]byte
+
+	// Current is the ID of the key to encrypt with.
+	Current string
+}
+
+// Keyring builds a Keyring with one encryptor per key, using Current as the
+// primary. build is typically a constructor such as AES or XChaCha.
+func (m KeyMaterial) Keyring(build func(key []byte) (Encryptor, error)) (*Keyring, error) {
+	keys := make(map[string]Encryptor, len(m.Keys))
+	for kid, key := range m.Keys {
+		enc, err := build(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", kid, err)
+		}
+		keys[kid] = enc
+	}
+	return NewKeyring(m.Current, keys)
+}
+
+// digest fingerprints the material so unchanged reloads can be skipped.
+func (m KeyMaterial) digest() [sha256.Size]byte {
+	ids := make([]string, 0, len(m.Keys))
+	for kid := range m.Keys {
+		ids = append(ids, kid)
+	}
+	slices.Sort(ids)
+
+	h := sha256.New()
+	for _, kid := range append(ids, m.Current) {
+		// Length prefixes keep ("ab", "c") and ("a", "bc") distinct.
+		h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(kid))))
+		h.Write([]byte(kid))
+		h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(m.Keys[kid]))))
+		h.Write(m.Keys[kid])
+	}
+	return [sha256.Size]byte(h.Sum(nil))
+}
+
+// zeroize wipes every key.
+func (m KeyMaterial) zeroize() {
+	for _, key := range m.Keys {
+		zeroize(key)
+	}
+}
+
+// KeySource yields key material. Each call to Load returns freshly read
+// bytes, which the caller owns and may zeroize.
+type KeySource interface {
+	Load(ctx context.Context) (KeyMaterial, error)
+}
+
+// KeySourceFunc adapts a function to a KeySource.
+type KeySourceFunc func(ctx context.Context) (KeyMaterial, error)
+
+// Load calls f(ctx).
+func (f KeySourceFunc) Load(ctx context.Context) (KeyMaterial, error) {
+	return f(ctx)
+}
+
+// EnvKeySource reads a base64-encoded key from the environment variable name.
+// The key ID is the variable name.
+func EnvKeySource(name string) KeySource {
+	return KeySourceFunc(func(context.Context) (KeyMaterial, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return KeyMaterial{}, fmt.Errorf("%w: environment variable %s is not set", ErrInvalidKey, name)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return KeyMaterial{}, fmt.Errorf("%w: environment variable %s is not valid base64: %w", ErrInvalidKey, name, err)
+		}
+		return KeyMaterial{Keys: map[string][]byte{name: key}, Current: name}, nil
+	})
+}
+
+// FileKeySource reads a key from the file at path, as-is. The key ID is the
+// file name. Build with FromPEM or FromJWK for text formats.
+func FileKeySource(path string) KeySource {
+	return KeySourceFunc(func(context.Context) (KeyMaterial, error) {
+		key, err := os.ReadFile(path) // #nosec G304 -- path is caller-controlled configuration
+		if err != nil {
+			return KeyMaterial{}, fmt.Errorf("read key file: %w", err)
+		}
+		kid := filepath.Base(path)
+		return KeyMaterial{Keys: map[string][]byte{kid: key}, Current: kid}, nil
+	})
+}
+
+// DirKeySource reads every regular file in dir as a versioned key, with the
+// file name as its key ID. The name that sorts last is current, so name
+// files by version (e.g., "v0002", "2025-06-01"). Names starting with "."
+// are skipped, which ignores the bookkeeping entries of mounted secrets.
+func DirKeySource(dir string) KeySource {
+	return KeySourceFunc(func(context.Context) (KeyMaterial, error) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return KeyMaterial{}, fmt.Errorf("read key directory: %w", err)
+		}
+
+		m := KeyMaterial{Keys: make(map[string][]byte)}
+		for _, entry := range entries { // sorted by name
+			name := entry.Name()
+			if strings.HasPrefix(name, ".") {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			info, err := os.Stat(path) // follows symlinks
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			key, err := os.ReadFile(path) // #nosec G304 -- path is within a caller-controlled directory
+			if err != nil {
+				m.zeroize()
+				return KeyMaterial{}, fmt.Errorf("read key file: %w", err)
+			}
+			m.Keys[name] = key
+			m.Current = name
+		}
+
+		if len(m.Keys) == 0 {
+			return KeyMaterial{}, fmt.Errorf("%w: no key files in %s", ErrInvalidKey, dir)
+		}
+		return m, nil
+	})
+}
+
+// KeyReloaderConfig configures a KeyReloader.
+type KeyReloaderConfig struct {
+	// Name identifies the key source in signals (e.g., "aes").
+	Name string
+
+	// Source yields the key material. Required.
+	Source KeySource
+
+	// Build turns key material into an encryptor. Required. It must not
+	// retain the key bytes: they are zeroized after Build returns.
+	Build func(KeyMaterial) (Encryptor, error)
+
+	// Apply installs a new encryptor, typically by calling SetEncryptor on
+	// one or more processors. Required.
+	Apply func(Encryptor)
+
+	// Interval is the polling period for Run. Defaults to one minute.
+	Interval time.Duration
+}
+
+// KeyReloader polls a KeySource and installs a rebuilt encryptor whenever
+// the key material changes.
+//
+// A failed load or build leaves the current encryptor in place, so the
+// processor keeps serving the last good keys. Every rotation emits
+// SignalKeyRotated; failures emit it at error level with the error.
+type KeyReloader struct {
+	cfg KeyReloaderConfig
+
+	mu      sync.Mutex
+	digest  [sha256.Size]byte
+	applied bool
+}
+
+// NewKeyReloader returns a reloader for cfg. Call Reload once at startup to
+// install the initial encryptor, then Run to keep it current.
+func NewKeyReloader(cfg KeyReloaderConfig) (*KeyReloader, error) {
+	if cfg.Source == nil || cfg.Build == nil || cfg.Apply == nil {
+		return nil, errors.New("key reloader: Source, Build, and Apply are required")
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultReloadInterval
+	}
+	return &KeyReloader{cfg: cfg}, nil
+}
+
+// Reload reads the source once and installs a new encryptor if the
+// material changed. It returns an error, and keeps the current encryptor,
+// if the source or Build fails.
+func (r *KeyReloader) Reload(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, err := r.cfg.Source.Load(ctx)
+	if err != nil {
+		emitKeyRotated(ctx, r.cfg.Name, "", err)
+		return err
+	}
+	defer m.zeroize()
+
+	digest := m.digest()
+	if r.applied && digest == r.digest {
+		return nil
+	}
+
+	enc, err := r.cfg.Build(m)
+	if err != nil {
+		emitKeyRotated(ctx, r.cfg.Name, m.Current, err)
+		return err
+	}
+
+	r.cfg.Apply(enc)
+	r.digest = digest
+	r.applied = true
+	emitKeyRotated(ctx, r.cfg.Name, m.Current, nil)
+	return nil
+}
+
+// Run calls Reload every Interval until ctx is done, then returns ctx.Err().
+// Reload errors are reported through signals and do not stop Run.
+func (r *KeyReloader) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			_ = r.Reload(ctx) //nolint:errcheck // reported via SignalKeyRotated
+		}
+	}
+}
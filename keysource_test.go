@@ -0,0 +1,238 @@
+package cereal
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	testKeyV1 = []byte("32-byte-key-for-aes-256-encrypt!")
+	testKeyV2 = []byte("another-32-byte-key-for-aes-256!")
+)
+
+func TestEnvKeySource(t *testing.T) {
+	t.Setenv("CEREAL_TEST_KEY", base64.StdEncoding.EncodeToString(testKeyV1)+"\n")
+
+	m, err := EnvKeySource("CEREAL_TEST_KEY").Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if m.Current != "CEREAL_TEST_KEY" || string(m.Keys[m.Current]) != string(testKeyV1) {
+		t.Errorf("Load() = %+v", m)
+	}
+
+	if _, err := EnvKeySource("CEREAL_TEST_UNSET").Load(context.Background()); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("unset variable error = %v, want ErrInvalidKey", err)
+	}
+
+	t.Setenv("CEREAL_TEST_KEY", "not base64!")
+	if _, err := EnvKeySource("CEREAL_TEST_KEY").Load(context.Background()); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("invalid base64 error = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestFileKeySource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aes.key")
+	if err := os.WriteFile(path, testKeyV1, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := FileKeySource(path).Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if m.Current != "aes.key" || string(m.Keys["aes.key"]) != string(testKeyV1) {
+		t.Errorf("Load() = %+v", m)
+	}
+
+	if _, err := FileKeySource(path + ".missing").Load(context.Background()); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestDirKeySource(t *testing.T) {
+	dir := t.TempDir()
+	for name, key := range map[string][]byte{"v0001": testKeyV1, "v0002": testKeyV2, ".data": []byte("skip")} {
+		if err := os.WriteFile(filepath.Join(dir, name), key, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "v9999"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := DirKeySource(dir).Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if m.Current != "v0002" {
+		t.Errorf("Current = %q, want v0002", m.Current)
+	}
+	if len(m.Keys) != 2 {
+		t.Errorf("loaded %d keys, want 2", len(m.Keys))
+	}
+
+	if _, err := DirKeySource(t.TempDir()).Load(context.Background()); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("empty directory error = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestKeyMaterial_Keyring(t *testing.T) {
+	m := KeyMaterial{Keys: map[string][]byte{"v1": testKeyV1, "v2": testKeyV2}, Current: "v2"}
+	ring, err := m.Keyring(AES)
+	if err != nil {
+		t.Fatalf("Keyring() error: %v", err)
+	}
+	if ring.Primary() != "v2" || len(ring.KeyIDs()) != 2 {
+		t.Errorf("Keyring() primary = %q, ids = %v", ring.Primary(), ring.KeyIDs())
+	}
+
+	bad := KeyMaterial{Keys: map[string][]byte{"v1": []byte("short")}, Current: "v1"}
+	if _, err := bad.Keyring(AES); err == nil {
+		t.Error("expected error for invalid key")
+	}
+}
+
+func TestNewKeyReloader_Validation(t *testing.T) {
+	if _, err := NewKeyReloader(KeyReloaderConfig{}); err == nil {
+		t.Error("expected error for empty config")
+	}
+}
+
+// reloadTarget records the encryptors a KeyReloader applies to a processor.
+type reloadTarget struct {
+	proc    *Processor[EncryptUser]
+	applied int
+}
+
+func newReloadTarget(t *testing.T) *reloadTarget {
+	t.Helper()
+	proc, err := NewProcessor[EncryptUser]()
+	if err != nil {
+		t.Fatalf("NewProcessor() error: %v", err)
+	}
+	return &reloadTarget{proc: proc}
+}
+
+func (r *reloadTarget) apply(enc Encryptor) {
+	r.applied++
+	r.proc.SetEncryptor(EncryptAES, enc)
+}
+
+func TestKeyReloader_Reload(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	write := func(name string, key []byte) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), key, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("v0001", testKeyV1)
+
+	target := newReloadTarget(t)
+	reloader, err := NewKeyReloader(KeyReloaderConfig{
+		Name:   "aes",
+		Source: DirKeySource(dir),
+		Build:  func(m KeyMaterial) (Encryptor, error) { return m.Keyring(AES) },
+		Apply:  target.apply,
+	})
+	if err != nil {
+		t.Fatalf("NewKeyReloader() error: %v", err)
+	}
+
+	if err := reloader.Reload(ctx); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	stored, err := target.proc.Store(ctx, EncryptUser{ID: "1", Email: testEmail})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+
+	// Unchanged material is not reapplied.
+	if err := reloader.Reload(ctx); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	if target.applied != 1 {
+		t.Errorf("applied %d times, want 1", target.applied)
+	}
+
+	// A new key version rotates, and data under the old key stays readable.
+	write("v0002", testKeyV2)
+	if err := reloader.Reload(ctx); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	if target.applied != 2 {
+		t.Errorf("applied %d times, want 2", target.applied)
+	}
+	loaded, err := target.proc.Load(ctx, stored)
+	if err != nil {
+		t.Fatalf("Load() after rotation error: %v", err)
+	}
+	if loaded.Email != testEmail {
+		t.Errorf("Email = %q, want %q", loaded.Email, testEmail)
+	}
+
+	// A bad key fails the reload and keeps the current encryptor.
+	write("v0003", []byte("short"))
+	if err := reloader.Reload(ctx); err == nil {
+		t.Error("expected error for invalid key")
+	}
+	if target.applied != 2 {
+		t.Errorf("applied %d times after failure, want 2", target.applied)
+	}
+	if _, err := target.proc.Load(ctx, stored); err != nil {
+		t.Errorf("Load() after failed reload error: %v", err)
+	}
+}
+
+func TestKeyReloader_SourceError(t *testing.T) {
+	target := newReloadTarget(t)
+	reloader, _ := NewKeyReloader(KeyReloaderConfig{
+		Source: KeySourceFunc(func(context.Context) (KeyMaterial, error) {
+			return KeyMaterial{}, errors.New("vault unavailable")
+		}),
+		Build: func(m KeyMaterial) (Encryptor, error) { return AES(m.Keys[m.Current]) },
+		Apply: target.apply,
+	})
+
+	if err := reloader.Reload(context.Background()); err == nil {
+		t.Error("expected source error")
+	}
+	if target.applied != 0 {
+		t.Errorf("applied %d times, want 0", target.applied)
+	}
+}
+
+func TestKeyReloader_Run(t *testing.T) {
+	loads := make(chan struct{}, 8)
+	target := newReloadTarget(t)
+	reloader, _ := NewKeyReloader(KeyReloaderConfig{
+		Source: KeySourceFunc(func(context.Context) (KeyMaterial, error) {
+			select {
+			case loads <- struct{}{}:
+			default:
+			}
+			return KeyMaterial{Keys: map[string][]byte{"k": append([]byte(nil), testKeyV1...)}, Current: "k"}, nil
+		}),
+		Build:    func(m KeyMaterial) (Encryptor, error) { return AES(m.Keys[m.Current]) },
+		Apply:    target.apply,
+		Interval: time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- reloader.Run(ctx) }()
+
+	<-loads
+	<-loads
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,108 @@
+package cereal
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// KeyWrapper protects data keys with a key-encryption key (KEK).
+//
+// Envelope encryption uses a KeyWrapper to wrap each per-message data key.
+// Implementations backed by a KMS or HSM keep the KEK out of process memory;
+// adapters for cloud providers can live outside this module.
+type KeyWrapper interface {
+	// WrapKey encrypts a data key and returns the wrapped form.
+	WrapKey(ctx context.Context, dataKey []byte) ([]byte, error)
+
+	// UnwrapKey decrypts a wrapped data key.
+	UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// aesKeyWrapper wraps data keys with an in-memory AES-GCM key.
+type aesKeyWrapper struct {
+	gcm cipher.AEAD
+}
+
+// AESKeyWrapper returns a KeyWrapper that wraps data keys with AES-GCM.
+// KEK must be 16, 24, or 32 bytes.
+//
+// The KEK is held in process memory. Prefer a KMS-backed KeyWrapper in production.
+func AESKeyWrapper(kek []byte) (KeyWrapper, error) {
+	gcm, err := newAEAD(EncryptAES, kek)
+	if err != nil {
+		return nil, err
+	}
+	return &aesKeyWrapper{gcm: gcm}, nil
+}
+
+func (w *aesKeyWrapper) WrapKey(_ context.Context, dataKey []byte) ([]byte, error) {
+	nonce := make([]byte, w.gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	// Format: [nonce][sealed data key]
+	return w.gcm.Seal(nonce, nonce, dataKey, nil), nil
+}
+
+func (w *aesKeyWrapper) UnwrapKey(_ context.Context, wrapped []byte) ([]byte, error) {
+	nonceSize := w.gcm.NonceSize()
+	if len(wrapped) < nonceSize {
+		return nil, ErrCiphertextShort
+	}
+
+	nonce, sealed := wrapped[:nonceSize], wrapped[nonceSize:]
+	return w.gcm.Open(nil, nonce, sealed, nil)
+}
+
+// LocalKeyWrapper returns a file-backed KeyWrapper for development and tests.
+//
+// The file holds a base64-encoded AES-256 KEK. If the file does not exist,
+// a random KEK is generated and written with 0600 permissions. Do not use
+// in production: the KEK sits on disk and in process memory.
+func LocalKeyWrapper(path string) (KeyWrapper, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is caller-controlled configuration
+	if errors.Is(err, fs.ErrNotExist) {
+		return createLocalKeyWrapper(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+	defer zeroize(data)
+
+	encoded := bytes.TrimSpace(data)
+	kek := make([]byte, base64.StdEncoding.DecodedLen(len(encoded)))
+	defer zeroize(kek)
+	n, err := base64.StdEncoding.Decode(kek, encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: key file is not valid base64: %w", ErrInvalidKey, err)
+	}
+
+	return AESKeyWrapper(kek[:n])
+}
+
+// createLocalKeyWrapper generates a new KEK and persists it to path.
+func createLocalKeyWrapper(path string) (KeyWrapper, error) {
+	kek := make([]byte, 32)
+	defer zeroize(kek)
+	if _, err := io.ReadFull(rand.Reader, kek); err != nil {
+		return nil, err
+	}
+
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(kek)), base64.StdEncoding.EncodedLen(len(kek))+1)
+	defer zeroize(encoded)
+	base64.StdEncoding.Encode(encoded, kek)
+	if err := os.WriteFile(path, append(encoded, '\n'), 0o600); err != nil {
+		return nil, fmt.Errorf("write key file: %w", err)
+	}
+
+	return AESKeyWrapper(kek)
+}
@@ -0,0 +1,223 @@
+package cereal
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAESKeyWrapper_RoundTrip(t *testing.T) {
+	w, err := AESKeyWrapper([]byte("32-byte-key-encryption-key-here!"))
+	if err != nil {
+		t.Fatalf("AESKeyWrapper() error: %v", err)
+	}
+
+	ctx := context.Background()
+	dataKey := []byte("0123456789abcdef0123456789abcdef")
+
+	wrapped, err := w.WrapKey(ctx, dataKey)
+	if err != nil {
+		t.Fatalf("WrapKey() error: %v", err)
+	}
+	if bytes.Contains(wrapped, dataKey) {
+		t.Error("wrapped key should not contain the data key")
+	}
+
+	unwrapped, err := w.UnwrapKey(ctx, wrapped)
+	if err != nil {
+		t.Fatalf("UnwrapKey() error: %v", err)
+	}
+	if !bytes.Equal(unwrapped, dataKey) {
+		t.Error("unwrapped key does not match data key")
+	}
+
+	if _, err := w.UnwrapKey(ctx, wrapped[:4]); !errors.Is(err, ErrCiphertextShort) {
+		t.Errorf("expected ErrCiphertextShort, got %v", err)
+	}
+}
+
+func TestAESKeyWrapper_InvalidKeySize(t *testing.T) {
+	if _, err := AESKeyWrapper([]byte("short")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestLocalKeyWrapper_CreatesAndReloadsKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kek")
+
+	w1, err := LocalKeyWrapper(path)
+	if err != nil {
+		t.Fatalf("LocalKeyWrapper() error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("key file not created: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("key file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	ctx := context.Background()
+	wrapped, _ := w1.WrapKey(ctx, []byte("data-key"))
+
+	// A second wrapper loads the same KEK from disk
+	w2, err := LocalKeyWrapper(path)
+	if err != nil {
+		t.Fatalf("LocalKeyWrapper() reload error: %v", err)
+	}
+	unwrapped, err := w2.UnwrapKey(ctx, wrapped)
+	if err != nil {
+		t.Fatalf("UnwrapKey() error: %v", err)
+	}
+	if string(unwrapped) != "data-key" {
+		t.Errorf("UnwrapKey() = %q, want %q", unwrapped, "data-key")
+	}
+}
+
+func TestLocalKeyWrapper_InvalidFile(t *testing.T) {
+	dir := t.TempDir()
+
+	notBase64 := filepath.Join(dir, "bad")
+	if err := os.WriteFile(notBase64, []byte("not base64!"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LocalKeyWrapper(notBase64); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey for invalid base64, got %v", err)
+	}
+
+	wrongSize := filepath.Join(dir, "short")
+	encoded := base64.StdEncoding.EncodeToString([]byte("short"))
+	if err := os.WriteFile(wrongSize, []byte(encoded), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LocalKeyWrapper(wrongSize); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey for wrong key size, got %v", err)
+	}
+}
+
+// recordingWrapper counts wrap/unwrap calls and delegates to an AES wrapper.
+type recordingWrapper struct {
+	KeyWrapper
+	wraps, unwraps int
+	fail           error
+}
+
+func (w *recordingWrapper) WrapKey(ctx context.Context, dataKey []byte) ([]byte, error) {
+	w.wraps++
+	if w.fail != nil {
+		return nil, w.fail
+	}
+	return w.KeyWrapper.WrapKey(ctx, dataKey)
+}
+
+func (w *recordingWrapper) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	w.unwraps++
+	if w.fail != nil {
+		return nil, w.fail
+	}
+	return w.KeyWrapper.UnwrapKey(ctx, wrapped)
+}
+
+func TestEnvelopeWithWrapper(t *testing.T) {
+	masterKey := []byte("32-byte-master-key-for-envelope!")
+	inner, _ := AESKeyWrapper(masterKey)
+	w := &recordingWrapper{KeyWrapper: inner}
+
+	enc, err := EnvelopeWithWrapper(w, EnvelopeConfig{})
+	if err != nil {
+		t.Fatalf("EnvelopeWithWrapper() error: %v", err)
+	}
+
+	ciphertext, err := enc.Encrypt([]byte("hello"))
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+	plaintext, err := enc.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt() error: %v", err)
+	}
+	if string(plaintext) != "hello" {
+		t.Errorf("Decrypt() = %q, want %q", plaintext, "hello")
+	}
+	if w.wraps != 1 || w.unwraps != 1 {
+		t.Errorf("wraps=%d unwraps=%d, want 1 each", w.wraps, w.unwraps)
+	}
+
+	// An AES wrapper produces the same format as Envelope(masterKey)
+	legacy, _ := Envelope(masterKey)
+	plaintext, err = legacy.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Envelope.Decrypt() error: %v", err)
+	}
+	if string(plaintext) != "hello" {
+		t.Errorf("Envelope.Decrypt() = %q, want %q", plaintext, "hello")
+	}
+}
+
+func TestEnvelopeWithWrapper_Errors(t *testing.T) {
+	if _, err := EnvelopeWithWrapper(nil, EnvelopeConfig{}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey for nil wrapper, got %v", err)
+	}
+
+	inner, _ := AESKeyWrapper([]byte("32-byte-master-key-for-envelope!"))
+	w := &recordingWrapper{KeyWrapper: inner}
+	enc, _ := EnvelopeWithWrapper(w, EnvelopeConfig{})
+	ciphertext, _ := enc.Encrypt([]byte("hello"))
+
+	kmsDown := errors.New("kms unavailable")
+	w.fail = kmsDown
+
+	if _, err := enc.Encrypt([]byte("hello")); !errors.Is(err, kmsDown) {
+		t.Errorf("expected wrapper error, got %v", err)
+	}
+
+	_, err := enc.Decrypt(ciphertext)
+	if !errors.Is(err, ErrDecrypt) || !errors.Is(err, kmsDown) {
+		t.Errorf("expected ErrDecrypt wrapping wrapper error, got %v", err)
+	}
+}
+
+// ctxWrapper records the context value passed to the wrapper.
+type ctxWrapper struct {
+	KeyWrapper
+	seen []any
+}
+
+func (w *ctxWrapper) WrapKey(ctx context.Context, dataKey []byte) ([]byte, error) {
+	w.seen = append(w.seen, ctx.Value(ctxKey{}))
+	return w.KeyWrapper.WrapKey(ctx, dataKey)
+}
+
+func (w *ctxWrapper) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	w.seen = append(w.seen, ctx.Value(ctxKey{}))
+	return w.KeyWrapper.UnwrapKey(ctx, wrapped)
+}
+
+func TestEnvelope_PassesContextToWrapper(t *testing.T) {
+	inner, _ := AESKeyWrapper([]byte("32-byte-master-key-for-envelope!"))
+	w := &ctxWrapper{KeyWrapper: inner}
+	enc, _ := EnvelopeWithWrapper(w, EnvelopeConfig{})
+
+	ec, ok := enc.(EncryptorContext)
+	if !ok {
+		t.Fatal("envelope encryptor should implement EncryptorContext")
+	}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "trace-1")
+	ciphertext, err := ec.EncryptContext(ctx, []byte("hello"))
+	if err != nil {
+		t.Fatalf("EncryptContext() error: %v", err)
+	}
+	if _, err := ec.DecryptContext(ctx, ciphertext); err != nil {
+		t.Fatalf("DecryptContext() error: %v", err)
+	}
+
+	if len(w.seen) != 2 || w.seen[0] != "trace-1" || w.seen[1] != "trace-1" {
+		t.Errorf("context values seen = %v, want [trace-1 trace-1]", w.seen)
+	}
+}
@@ -0,0 +1,256 @@
+package cereal
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// bcryptMemoryCost approximates the memory one bcrypt hash uses: the
+// Blowfish state, which is fixed regardless of cost.
+const bcryptMemoryCost = 4 << 10
+
+// HashCost is implemented by hashers that report how much memory one hash
+// allocates. HashLimiter weighs each hash by it. Argon2 and Bcrypt
+// hashers implement it.
+type HashCost interface {
+	// MemoryCost returns the memory one Hash call uses, in bytes.
+	MemoryCost() int64
+}
+
+// verifyCost is implemented by verifiers whose memory use depends on the
+// parameters stored in the hash being verified, as with Argon2.
+type verifyCost interface {
+	verifyMemoryCost(encoded string) int64
+}
+
+// MemoryCost returns the Argon2 memory parameter in bytes.
+func (h *argon2Hasher) MemoryCost() int64 {
+	return int64(h.params.Memory) * 1024
+}
+
+// MemoryCost returns the size of the bcrypt Blowfish state.
+func (h *bcryptHasher) MemoryCost() int64 {
+	return bcryptMemoryCost
+}
+
+// HashLimiterConfig configures a HashLimiter.
+type HashLimiterConfig struct {
+	// Budget is the total memory, in bytes, that concurrent hashes may use.
+	// Required.
+	Budget int64
+
+	// MaxWaiters bounds how many hashes may queue for budget. Further hashes
+	// fail with ErrHashSaturated at once. Zero means no bound.
+	MaxWaiters int
+
+	// MaxWait bounds how long a hash queues for budget, in addition to the
+	// operation's context deadline. Zero means no bound.
+	MaxWait time.Duration
+}
+
+// HashLimiter admits password hashes against a shared memory budget.
+//
+// Each hash acquires its hasher's MemoryCost from the budget before it runs
+// and returns it afterwards. Hashes that do not fit queue in FIFO order
+// until budget frees up, the operation's context ends, or MaxWait elapses;
+// they then fail with ErrHashSaturated. A single limiter can be shared by
+// several hashers and processors so that they draw on one budget.
+//
+// Every admission emits SignalHashAdmission with the time spent queued.
+// Safe for concurrent use.
+type HashLimiter struct {
+	cfg HashLimiterConfig
+
+	mu      sync.Mutex
+	used    int64
+	waiters *list.List // of *hashWaiter, front is next to be admitted
+}
+
+// hashWaiter is a hash queued for budget. ready is closed once admitted.
+type hashWaiter struct {
+	weight int64
+	ready  chan struct{}
+}
+
+// NewHashLimiter creates a limiter with the given budget.
+func NewHashLimiter(cfg HashLimiterConfig) (*HashLimiter, error) {
+	if cfg.Budget <= 0 {
+		return nil, errors.New("hash limiter: Budget must be positive")
+	}
+	return &HashLimiter{cfg: cfg, waiters: list.New()}, nil
+}
+
+// Limit returns a hasher that admits each hash, and each Verify if h is a
+// Verifier, through the limiter, weighed by h's MemoryCost. An Argon2
+// Verify is weighed by the memory parameter of the hash it checks instead.
+// A hash that costs more than the whole budget is weighed at the budget, so
+// it runs alone rather than never.
+//
+// Hashers that do not implement HashCost, such as SHA256Hasher, are cheap
+// and are returned unchanged.
+//
+//	limiter, _ := cereal.NewHashLimiter(cereal.HashLimiterConfig{Budget: 256 << 20})
+//	proc.SetHasher(cereal.HashArgon2, limiter.Limit(cereal.Argon2()))
+func (l *HashLimiter) Limit(h Hasher) Hasher {
+	hc, ok := h.(HashCost)
+	if !ok {
+		return h
+	}
+	weight := min(hc.MemoryCost(), l.cfg.Budget)
+	limited := &limitedHasher{inner: h, limiter: l, weight: weight}
+	if v, ok := h.(Verifier); ok {
+		// Verifying costs as much as hashing, so it is admitted the same way.
+		return &limitedVerifier{limitedHasher: limited, verifier: v}
+	}
+	return limited
+}
+
+// acquire takes weight from the budget, queueing until it fits. It returns
+// ErrHashSaturated if the queue is full or ctx ends while waiting.
+func (l *HashLimiter) acquire(ctx context.Context, weight int64) error {
+	start := time.Now()
+
+	l.mu.Lock()
+	if l.waiters.Len() == 0 && l.used+weight <= l.cfg.Budget {
+		l.used += weight
+		l.mu.Unlock()
+		emitHashAdmission(ctx, weight, 0, nil)
+		return nil
+	}
+	if l.cfg.MaxWaiters > 0 && l.waiters.Len() >= l.cfg.MaxWaiters {
+		l.mu.Unlock()
+		err := fmt.Errorf("%w: %d hashes queued", ErrHashSaturated, l.cfg.MaxWaiters)
+		emitHashAdmission(ctx, weight, 0, err)
+		return err
+	}
+	w := &hashWaiter{weight: weight, ready: make(chan struct{})}
+	elem := l.waiters.PushBack(w)
+	l.mu.Unlock()
+
+	wait := ctx
+	if l.cfg.MaxWait > 0 {
+		var cancel context.CancelFunc
+		wait, cancel = context.WithTimeout(ctx, l.cfg.MaxWait)
+		defer cancel()
+	}
+
+	select {
+	case <-w.ready:
+		emitHashAdmission(ctx, weight, time.Since(start), nil)
+		return nil
+	case <-wait.Done():
+	}
+
+	l.mu.Lock()
+	select {
+	case <-w.ready:
+		// Admitted while giving up; keep the budget rather than hand it back.
+		l.mu.Unlock()
+		emitHashAdmission(ctx, weight, time.Since(start), nil)
+		return nil
+	default:
+	}
+	isFront := l.waiters.Front() == elem
+	l.waiters.Remove(elem)
+	if isFront {
+		// Hashes behind this one may fit now.
+		l.admit()
+	}
+	l.mu.Unlock()
+
+	err := fmt.Errorf("%w: %w", ErrHashSaturated, wait.Err())
+	emitHashAdmission(ctx, weight, time.Since(start), err)
+	return err
+}
+
+// release returns weight to the budget and admits queued hashes that fit.
+func (l *HashLimiter) release(weight int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.used -= weight
+	l.admit()
+}
+
+// admit wakes queued hashes in order while they fit. Caller must hold l.mu.
+func (l *HashLimiter) admit() {
+	for {
+		front := l.waiters.Front()
+		if front == nil {
+			return
+		}
+		w, _ := front.Value.(*hashWaiter)
+		if l.used+w.weight > l.cfg.Budget {
+			// Strict FIFO, so a large hash is not starved by smaller ones.
+			return
+		}
+		l.used += w.weight
+		l.waiters.Remove(front)
+		close(w.ready)
+	}
+}
+
+// limitedHasher runs a hasher under a HashLimiter.
+type limitedHasher struct {
+	inner   Hasher
+	limiter *HashLimiter
+	weight  int64
+}
+
+func (h *limitedHasher) Hash(plaintext []byte) (string, error) {
+	return h.HashContext(context.Background(), plaintext)
+}
+
+// HashContext waits for budget, honoring ctx cancellation and deadline,
+// then hashes with the wrapped hasher.
+func (h *limitedHasher) HashContext(ctx context.Context, plaintext []byte) (string, error) {
+	if err := h.limiter.acquire(ctx, h.weight); err != nil {
+		return "", err
+	}
+	defer h.limiter.release(h.weight)
+	return hashContext(ctx, h.inner, plaintext)
+}
+
+// MemoryCost returns the weight the hasher is admitted with.
+func (h *limitedHasher) MemoryCost() int64 {
+	return h.weight
+}
+
+// Destroy destroys the wrapped hasher if it implements Destroyer.
+func (h *limitedHasher) Destroy() {
+	if d, ok := h.inner.(Destroyer); ok {
+		d.Destroy()
+	}
+}
+
+// limitedVerifier is a limitedHasher whose wrapped hasher is a Verifier.
+type limitedVerifier struct {
+	*limitedHasher
+	verifier Verifier
+}
+
+func (v *limitedVerifier) Verify(encoded string, plaintext []byte) (bool, error) {
+	return v.VerifyContext(context.Background(), encoded, plaintext)
+}
+
+// VerifyContext waits for budget, honoring ctx cancellation and deadline,
+// then verifies with the wrapped hasher.
+func (v *limitedVerifier) VerifyContext(ctx context.Context, encoded string, plaintext []byte) (bool, error) {
+	weight := v.weight
+	if vc, ok := v.verifier.(verifyCost); ok {
+		weight = min(vc.verifyMemoryCost(encoded), v.limiter.cfg.Budget)
+	}
+	if err := v.limiter.acquire(ctx, weight); err != nil {
+		return false, err
+	}
+	defer v.limiter.release(weight)
+	return verifyContext(ctx, v.verifier, encoded, plaintext)
+}
+
+// NeedsRehash only parses encoded, so it is not limited.
+func (v *limitedVerifier) NeedsRehash(encoded string) (bool, error) {
+	return v.verifier.NeedsRehash(encoded)
+}
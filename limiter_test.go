@@ -0,0 +1,211 @@
+package cereal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// costlyHasher is a blocking hasher that reports a memory cost.
+type costlyHasher struct {
+	cost    int64
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newCostlyHasher(cost int64) *costlyHasher {
+	return &costlyHasher{cost: cost, entered: make(chan struct{}, 8), release: make(chan struct{})}
+}
+
+func (h *costlyHasher) Hash([]byte) (string, error) {
+	h.entered <- struct{}{}
+	<-h.release
+	return "hashed", nil
+}
+
+func (h *costlyHasher) MemoryCost() int64 { return h.cost }
+
+func TestNewHashLimiter_Validation(t *testing.T) {
+	if _, err := NewHashLimiter(HashLimiterConfig{}); err == nil {
+		t.Error("expected error for zero budget")
+	}
+}
+
+func TestHashLimiter_Limit(t *testing.T) {
+	limiter, _ := NewHashLimiter(HashLimiterConfig{Budget: 32 << 20})
+
+	if h := SHA256Hasher(); limiter.Limit(h) != h {
+		t.Error("Limit() should return hashers without a memory cost unchanged")
+	}
+
+	// 64 MiB exceeds the 32 MiB budget, so it is weighed at the budget.
+	limited, ok := limiter.Limit(Argon2()).(HashCost)
+	if !ok || limited.MemoryCost() != 32<<20 {
+		t.Errorf("Limit(Argon2()) weight = %v", limited)
+	}
+
+	hash, err := limiter.Limit(BcryptWithCost(BcryptMinCost)).Hash([]byte("secret"))
+	if err != nil || hash == "" {
+		t.Errorf("Hash() = %q, %v", hash, err)
+	}
+}
+
+func TestHashLimiter_WaitsForBudget(t *testing.T) {
+	limiter, _ := NewHashLimiter(HashLimiterConfig{Budget: 100})
+	slow := newCostlyHasher(60)
+	limited := limiter.Limit(slow)
+
+	done := make(chan error, 2)
+	hash := func() {
+		_, err := limited.Hash([]byte("secret"))
+		done <- err
+	}
+	go hash()
+	<-slow.entered
+
+	// The second hash does not fit until the first releases its budget.
+	go hash()
+	select {
+	case <-slow.entered:
+		t.Fatal("second hash ran over budget")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	slow.release <- struct{}{}
+	<-slow.entered
+	slow.release <- struct{}{}
+	for range 2 {
+		if err := <-done; err != nil {
+			t.Errorf("Hash() error: %v", err)
+		}
+	}
+}
+
+func TestHashLimiter_Saturated(t *testing.T) {
+	slow := newCostlyHasher(100)
+	defer close(slow.release)
+
+	t.Run("context deadline", func(t *testing.T) {
+		limiter, _ := NewHashLimiter(HashLimiterConfig{Budget: 100})
+		limited := limiter.Limit(slow).(HasherContext)
+		go func() { _, _ = limited.HashContext(context.Background(), nil) }()
+		<-slow.entered
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := limited.HashContext(ctx, []byte("secret"))
+		if !errors.Is(err, ErrHashSaturated) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("HashContext() = %v, want ErrHashSaturated and DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("max wait", func(t *testing.T) {
+		limiter, _ := NewHashLimiter(HashLimiterConfig{Budget: 100, MaxWait: 10 * time.Millisecond})
+		limited := limiter.Limit(slow)
+		go func() { _, _ = limited.Hash(nil) }()
+		<-slow.entered
+
+		if _, err := limited.Hash([]byte("secret")); !errors.Is(err, ErrHashSaturated) {
+			t.Errorf("Hash() = %v, want ErrHashSaturated", err)
+		}
+	})
+
+	t.Run("queue full", func(t *testing.T) {
+		limiter, _ := NewHashLimiter(HashLimiterConfig{Budget: 100, MaxWaiters: 1})
+		limited := limiter.Limit(slow).(HasherContext)
+		go func() { _, _ = limited.HashContext(context.Background(), nil) }()
+		<-slow.entered
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		queued := make(chan error, 1)
+		go func() {
+			_, err := limited.HashContext(ctx, nil)
+			queued <- err
+		}()
+		for {
+			limiter.mu.Lock()
+			n := limiter.waiters.Len()
+			limiter.mu.Unlock()
+			if n == 1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		if _, err := limited.HashContext(context.Background(), nil); !errors.Is(err, ErrHashSaturated) {
+			t.Errorf("HashContext() with full queue = %v, want ErrHashSaturated", err)
+		}
+		cancel()
+		if err := <-queued; !errors.Is(err, context.Canceled) {
+			t.Errorf("queued HashContext() = %v, want context.Canceled", err)
+		}
+	})
+}
+
+func TestHashLimiter_CancelledWaiterUnblocksQueue(t *testing.T) {
+	limiter, _ := NewHashLimiter(HashLimiterConfig{Budget: 100})
+	big := newCostlyHasher(80)
+	small := newCostlyHasher(20)
+	defer close(big.release)
+
+	go func() { _, _ = limiter.Limit(big).Hash(nil) }()
+	<-big.entered
+
+	// A large hash at the front of the queue holds back smaller ones.
+	ctx, cancel := context.WithCancel(context.Background())
+	queued := make(chan error, 1)
+	go func() {
+		_, err := limiter.Limit(big).(HasherContext).HashContext(ctx, nil)
+		queued <- err
+	}()
+	for {
+		limiter.mu.Lock()
+		n := limiter.waiters.Len()
+		limiter.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := limiter.Limit(small).Hash(nil)
+		done <- err
+	}()
+
+	// Once it gives up, the small hash fits.
+	cancel()
+	<-queued
+	select {
+	case <-small.entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("small hash not admitted after the queue head cancelled")
+	}
+	close(small.release)
+	if err := <-done; err != nil {
+		t.Errorf("Hash() error: %v", err)
+	}
+}
+
+func TestProcessor_HashSaturated(t *testing.T) {
+	limiter, _ := NewHashLimiter(HashLimiterConfig{Budget: 100})
+	slow := newCostlyHasher(100)
+	defer close(slow.release)
+
+	proc, _ := NewProcessor[HashUser]()
+	proc.SetHasher(HashSHA256, limiter.Limit(slow))
+	go func() { _, _ = proc.Receive(context.Background(), HashUser{Password: "secret"}) }()
+	<-slow.entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := proc.Receive(ctx, HashUser{Password: "secret"})
+	if !errors.Is(err, ErrHashSaturated) {
+		t.Fatalf("Receive() = %v, want ErrHashSaturated", err)
+	}
+	if errors.Is(err, ErrHash) {
+		t.Error("saturation should not report ErrHash")
+	}
+}
@@ -1,6 +1,7 @@
 package cereal
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"unicode"
@@ -28,6 +29,22 @@ type Masker interface {
 	Mask(value string) (string, error)
 }
 
+// MaskerContext is implemented by maskers that honor the operation's context.
+// The Processor prefers MaskContext over Mask when available and passes
+// through the ctx given to Send or Encode.
+type MaskerContext interface {
+	// MaskContext applies masking to the value.
+	MaskContext(ctx context.Context, value string) (string, error)
+}
+
+// maskContext masks with m, preferring MaskerContext.
+func maskContext(ctx context.Context, m Masker, value string) (string, error) {
+	if mc, ok := m.(MaskerContext); ok {
+		return mc.MaskContext(ctx, value)
+	}
+	return m.Mask(value)
+}
+
 // ssnMasker masks SSN format: 123-45-6789 -> ***-**-6789
 type ssnMasker struct{}
 
@@ -0,0 +1,254 @@
+package cereal
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/mlkem"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// mlkemInfo binds derived keys to this hybrid scheme and format version.
+const mlkemInfo = "cereal mlkem768x25519 chacha20poly1305 v1"
+
+// Serialized hybrid key sizes.
+const (
+	// MLKEMPublicKeySize is the size of a serialized MLKEMPublicKey.
+	MLKEMPublicKeySize = mlkem.EncapsulationKeySize768 + 32
+
+	// MLKEMPrivateKeySize is the size of a serialized MLKEMPrivateKey.
+	MLKEMPrivateKeySize = mlkem.SeedSize + 32
+)
+
+// MLKEMPublicKey is a hybrid ML-KEM-768 + X25519 public key.
+type MLKEMPublicKey struct {
+	kem *mlkem.EncapsulationKey768
+	ecc *ecdh.PublicKey
+}
+
+// MLKEMPrivateKey is a hybrid ML-KEM-768 + X25519 private key.
+type MLKEMPrivateKey struct {
+	kem *mlkem.DecapsulationKey768
+	ecc *ecdh.PrivateKey
+}
+
+// GenerateMLKEMKey generates a new hybrid ML-KEM-768 + X25519 key pair.
+func GenerateMLKEMKey() (*MLKEMPrivateKey, error) {
+	kem, err := mlkem.GenerateKey768()
+	if err != nil {
+		return nil, err
+	}
+
+	ecc, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MLKEMPrivateKey{kem: kem, ecc: ecc}, nil
+}
+
+// ParseMLKEMPublicKey parses a public key produced by MLKEMPublicKey.Bytes.
+// Format: [1184 bytes ML-KEM-768 encapsulation key][32 bytes X25519 public key]
+func ParseMLKEMPublicKey(b []byte) (*MLKEMPublicKey, error) {
+	if len(b) != MLKEMPublicKeySize {
+		return nil, fmt.Errorf("%w: public key must be %d bytes, got %d", ErrInvalidKey, MLKEMPublicKeySize, len(b))
+	}
+
+	kem, err := mlkem.NewEncapsulationKey768(b[:mlkem.EncapsulationKeySize768])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	ecc, err := ecdh.X25519().NewPublicKey(b[mlkem.EncapsulationKeySize768:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	return &MLKEMPublicKey{kem: kem, ecc: ecc}, nil
+}
+
+// ParseMLKEMPrivateKey parses a private key produced by MLKEMPrivateKey.Bytes.
+// Format: [64 bytes ML-KEM-768 seed][32 bytes X25519 private key]
+func ParseMLKEMPrivateKey(b []byte) (*MLKEMPrivateKey, error) {
+	if len(b) != MLKEMPrivateKeySize {
+		return nil, fmt.Errorf("%w: private key must be %d bytes, got %d", ErrInvalidKey, MLKEMPrivateKeySize, len(b))
+	}
+
+	kem, err := mlkem.NewDecapsulationKey768(b[:mlkem.SeedSize])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	ecc, err := ecdh.X25519().NewPrivateKey(b[mlkem.SeedSize:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	return &MLKEMPrivateKey{kem: kem, ecc: ecc}, nil
+}
+
+// Bytes returns the serialized public key.
+func (k *MLKEMPublicKey) Bytes() []byte {
+	b := make([]byte, 0, MLKEMPublicKeySize)
+	b = append(b, k.kem.Bytes()...)
+	return append(b, k.ecc.Bytes()...)
+}
+
+// Bytes returns the serialized private key.
+// The result is secret key material and must be stored accordingly.
+func (k *MLKEMPrivateKey) Bytes() []byte {
+	b := make([]byte, 0, MLKEMPrivateKeySize)
+	b = append(b, k.kem.Bytes()...)
+	return append(b, k.ecc.Bytes()...)
+}
+
+// PublicKey returns the public half of the key pair.
+func (k *MLKEMPrivateKey) PublicKey() *MLKEMPublicKey {
+	return &MLKEMPublicKey{kem: k.kem.EncapsulationKey(), ecc: k.ecc.PublicKey()}
+}
+
+// mlkemEncryptor implements hybrid post-quantum public-key encryption.
+// The ML-KEM-768 and X25519 shared secrets are combined with HKDF-SHA256,
+// so the data stays confidential as long as either primitive holds.
+type mlkemEncryptor struct {
+	pub  *MLKEMPublicKey
+	priv *MLKEMPrivateKey
+}
+
+// MLKEM returns a hybrid ML-KEM-768 + X25519 encryptor.
+// pub is required for encryption; priv is required for decryption.
+// If priv is provided, pub may be nil and is derived from priv.
+//
+// Use for long-retention data that must resist "harvest now, decrypt later"
+// attacks by future quantum computers.
+// Format: [1088 bytes ML-KEM ciphertext][32 bytes ephemeral X25519 public key][ChaCha20-Poly1305 ciphertext]
+func MLKEM(pub *MLKEMPublicKey, priv *MLKEMPrivateKey) (Encryptor, error) {
+	if pub == nil && priv == nil {
+		return nil, fmt.Errorf("%w: public or private key required", ErrInvalidKey)
+	}
+	// Zero-value keys, not built by GenerateMLKEMKey or a parser
+	if pub != nil && (pub.kem == nil || pub.ecc == nil) {
+		return nil, fmt.Errorf("%w: empty public key", ErrInvalidKey)
+	}
+	if priv != nil && (priv.kem == nil || priv.ecc == nil) {
+		return nil, fmt.Errorf("%w: empty private key", ErrInvalidKey)
+	}
+
+	if priv != nil {
+		derived := priv.PublicKey()
+		if pub == nil {
+			pub = derived
+		} else if !pub.ecc.Equal(derived.ecc) || !bytes.Equal(pub.kem.Bytes(), derived.kem.Bytes()) {
+			return nil, fmt.Errorf("%w: public key does not match private key", ErrInvalidKey)
+		}
+	}
+
+	return &mlkemEncryptor{pub: pub, priv: priv}, nil
+}
+
+func (e *mlkemEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	kemShared, kemCiphertext := e.pub.kem.Encapsulate()
+
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	eccShared, err := ephemeral.ECDH(e.pub.ecc)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeralPub := ephemeral.PublicKey().Bytes()
+	aead, err := e.deriveAEAD(kemShared, eccShared, kemCiphertext, ephemeralPub)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, len(kemCiphertext)+len(ephemeralPub)+len(plaintext)+aead.Overhead())
+	header = append(header, kemCiphertext...)
+	header = append(header, ephemeralPub...)
+
+	// The derived key is unique per message, so a zero nonce is safe
+	nonce := make([]byte, aead.NonceSize())
+	return aead.Seal(header, nonce, plaintext, nil), nil
+}
+
+func (e *mlkemEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	if e.priv == nil {
+		return nil, fmt.Errorf("%w: private key required", ErrDecryptUnsupported)
+	}
+
+	headerSize := mlkem.CiphertextSize768 + 32
+	if len(ciphertext) < headerSize {
+		return nil, ErrCiphertextShort
+	}
+
+	kemCiphertext := ciphertext[:mlkem.CiphertextSize768]
+	ephemeralPub := ciphertext[mlkem.CiphertextSize768:headerSize]
+	sealed := ciphertext[headerSize:]
+
+	kemShared, err := e.priv.kem.Decapsulate(kemCiphertext)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecrypt, err)
+	}
+
+	ephemeral, err := ecdh.X25519().NewPublicKey(ephemeralPub)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid ephemeral key: %w", ErrDecrypt, err)
+	}
+
+	eccShared, err := e.priv.ecc.ECDH(ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecrypt, err)
+	}
+
+	aead, err := e.deriveAEAD(kemShared, eccShared, kemCiphertext, ephemeralPub)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	plaintext, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecrypt, err)
+	}
+
+	return plaintext, nil
+}
+
+// deriveAEAD combines both shared secrets into the per-message AEAD.
+// The salt binds the KEM ciphertext, ephemeral key, and recipient X25519 key.
+// The shared secrets and derived key are zeroized once the AEAD holds its copy.
+func (e *mlkemEncryptor) deriveAEAD(kemShared, eccShared, kemCiphertext, ephemeralPub []byte) (cipher.AEAD, error) {
+	secret := make([]byte, 0, len(kemShared)+len(eccShared))
+	secret = append(secret, kemShared...)
+	secret = append(secret, eccShared...)
+	zeroize(kemShared)
+	zeroize(eccShared)
+	defer zeroize(secret)
+
+	salt := make([]byte, 0, len(kemCiphertext)+64)
+	salt = append(salt, kemCiphertext...)
+	salt = append(salt, ephemeralPub...)
+	salt = append(salt, e.pub.ecc.Bytes()...)
+
+	key, err := hkdf.Key(sha256.New, secret, salt, mlkemInfo, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroize(key)
+
+	return chacha20poly1305.New(key)
+}
+
+// CanEncrypt reports whether a public key is configured.
+func (e *mlkemEncryptor) CanEncrypt() bool { return e.pub != nil }
+
+// CanDecrypt reports whether a private key is configured.
+func (e *mlkemEncryptor) CanDecrypt() bool { return e.priv != nil }
@@ -0,0 +1,169 @@
+package cereal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMLKEM_RoundTrip(t *testing.T) {
+	priv, err := GenerateMLKEMKey()
+	if err != nil {
+		t.Fatalf("GenerateMLKEMKey() error: %v", err)
+	}
+
+	enc, err := MLKEM(nil, priv)
+	if err != nil {
+		t.Fatalf("MLKEM() error: %v", err)
+	}
+
+	plaintext := []byte("patient record 4411")
+	ciphertext, err := enc.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+
+	decrypted, err := enc.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt() error: %v", err)
+	}
+
+	if !bytes.Equal(plaintext, decrypted) {
+		t.Errorf("round-trip failed: got %q, want %q", decrypted, plaintext)
+	}
+}
+
+func TestMLKEM_KeySerialization(t *testing.T) {
+	priv, _ := GenerateMLKEMKey()
+
+	pubBytes := priv.PublicKey().Bytes()
+	if len(pubBytes) != MLKEMPublicKeySize {
+		t.Errorf("public key size = %d, want %d", len(pubBytes), MLKEMPublicKeySize)
+	}
+	privBytes := priv.Bytes()
+	if len(privBytes) != MLKEMPrivateKeySize {
+		t.Errorf("private key size = %d, want %d", len(privBytes), MLKEMPrivateKeySize)
+	}
+
+	pub, err := ParseMLKEMPublicKey(pubBytes)
+	if err != nil {
+		t.Fatalf("ParseMLKEMPublicKey() error: %v", err)
+	}
+	parsedPriv, err := ParseMLKEMPrivateKey(privBytes)
+	if err != nil {
+		t.Fatalf("ParseMLKEMPrivateKey() error: %v", err)
+	}
+
+	if !bytes.Equal(parsedPriv.PublicKey().Bytes(), pubBytes) {
+		t.Error("parsed private key should derive the same public key")
+	}
+
+	// Encrypt with the parsed public key, decrypt with the parsed private key
+	writer, err := MLKEM(pub, nil)
+	if err != nil {
+		t.Fatalf("MLKEM() error: %v", err)
+	}
+	reader, err := MLKEM(pub, parsedPriv)
+	if err != nil {
+		t.Fatalf("MLKEM() error: %v", err)
+	}
+
+	ciphertext, _ := writer.Encrypt([]byte("hello"))
+	decrypted, err := reader.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt() error: %v", err)
+	}
+	if string(decrypted) != "hello" {
+		t.Errorf("Decrypt() = %q, want %q", decrypted, "hello")
+	}
+}
+
+func TestMLKEM_ParseInvalidKeys(t *testing.T) {
+	if _, err := ParseMLKEMPublicKey([]byte("short")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+	if _, err := ParseMLKEMPrivateKey([]byte("short")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+	if _, err := MLKEM(nil, nil); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+	if _, err := MLKEM(&MLKEMPublicKey{}, nil); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey for zero public key, got %v", err)
+	}
+	if _, err := MLKEM(nil, &MLKEMPrivateKey{}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey for zero private key, got %v", err)
+	}
+
+	priv1, _ := GenerateMLKEMKey()
+	priv2, _ := GenerateMLKEMKey()
+	if _, err := MLKEM(priv2.PublicKey(), priv1); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey for mismatched keys, got %v", err)
+	}
+}
+
+func TestMLKEM_PublicKeyOnly(t *testing.T) {
+	priv, _ := GenerateMLKEMKey()
+	enc, _ := MLKEM(priv.PublicKey(), nil)
+
+	ciphertext, err := enc.Encrypt([]byte("hello"))
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+
+	if _, err := enc.Decrypt(ciphertext); !errors.Is(err, ErrDecryptUnsupported) {
+		t.Errorf("expected ErrDecryptUnsupported, got %v", err)
+	}
+}
+
+func TestMLKEM_WrongKeyAndTampering(t *testing.T) {
+	priv1, _ := GenerateMLKEMKey()
+	priv2, _ := GenerateMLKEMKey()
+	enc1, _ := MLKEM(nil, priv1)
+	enc2, _ := MLKEM(nil, priv2)
+
+	ciphertext, _ := enc1.Encrypt([]byte("hello"))
+	if _, err := enc2.Decrypt(ciphertext); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt for wrong key, got %v", err)
+	}
+
+	tampered := append([]byte{}, ciphertext...)
+	tampered[len(tampered)-1] ^= 0xff
+	if _, err := enc1.Decrypt(tampered); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt for tampered ciphertext, got %v", err)
+	}
+
+	if _, err := enc1.Decrypt(ciphertext[:100]); !errors.Is(err, ErrCiphertextShort) {
+		t.Errorf("expected ErrCiphertextShort, got %v", err)
+	}
+}
+
+// MLKEMUser has hybrid post-quantum encryption tags.
+type MLKEMUser struct {
+	Diagnosis string `json:"diagnosis" store.encrypt:"mlkem" load.decrypt:"mlkem"`
+}
+
+func (u MLKEMUser) Clone() MLKEMUser { return u }
+
+func TestMLKEM_Processor(t *testing.T) {
+	priv, _ := GenerateMLKEMKey()
+	enc, _ := MLKEM(nil, priv)
+
+	proc, _ := NewProcessor[MLKEMUser]()
+	proc.SetEncryptor(EncryptMLKEM, enc)
+
+	ctx := context.Background()
+	stored, err := proc.Store(ctx, MLKEMUser{Diagnosis: "hypertension"})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+
+	loaded, err := proc.Load(ctx, stored)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if loaded.Diagnosis != "hypertension" {
+		t.Errorf("Diagnosis = %q, want %q", loaded.Diagnosis, "hypertension")
+	}
+}
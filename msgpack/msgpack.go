@@ -28,3 +28,9 @@ func (c *msgpackCodec) Marshal(v any) ([]byte, error) {
 func (c *msgpackCodec) Unmarshal(data []byte, v any) error {
 	return msgpack.Unmarshal(data, v)
 }
+
+// BinaryStrings reports that strings carry arbitrary bytes, so encrypted
+// string fields can use cereal.EncodingBinary.
+func (c *msgpackCodec) BinaryStrings() bool {
+	return true
+}
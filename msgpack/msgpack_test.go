@@ -1,7 +1,11 @@
 package msgpack
 
 import (
+	"context"
 	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/zoobzio/cereal"
 )
 
 func TestNew(t *testing.T) {
@@ -298,3 +302,53 @@ func TestMarshal_ComplexTypes(t *testing.T) {
 		t.Error("round-trip failed for Pointer")
 	}
 }
+
+// secretRecord has an encrypted string field.
+type secretRecord struct {
+	ID    string `msgpack:"id"`
+	Email string `msgpack:"email" store.encrypt:"aes" load.decrypt:"aes"`
+}
+
+func (r secretRecord) Clone() secretRecord { return r }
+
+func TestProcessor_BinaryEncodingRoundTrip(t *testing.T) {
+	enc, err := cereal.AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	if err != nil {
+		t.Fatalf("AES() error: %v", err)
+	}
+	proc, err := cereal.NewProcessor[secretRecord]()
+	if err != nil {
+		t.Fatalf("NewProcessor() error: %v", err)
+	}
+	proc.SetCodec(New()).SetEncryptor(cereal.EncryptAES, enc).SetCiphertextEncoding(cereal.EncodingBinary)
+
+	const email = "alice@example.com"
+	ctx := context.Background()
+	data, err := proc.Write(ctx, &secretRecord{ID: "1", Email: email})
+	if err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+
+	// Raw ciphertext: 12-byte nonce, plaintext length, 16-byte tag.
+	var raw map[string]string
+	if err := msgpack.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if want := len(email) + 28; len(raw["email"]) != want {
+		t.Errorf("stored ciphertext = %d bytes, want %d raw bytes", len(raw["email"]), want)
+	}
+
+	got, err := proc.Read(ctx, data)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if got.Email != email {
+		t.Errorf("Email = %q, want %q", got.Email, email)
+	}
+
+	// Rows written as raw bytes stay readable after switching back to base64.
+	proc.SetCiphertextEncoding(cereal.EncodingStd)
+	if got, err := proc.Read(ctx, data); err != nil || got.Email != email {
+		t.Errorf("Read() after switching to std = %q, %v", got.Email, err)
+	}
+}
@@ -0,0 +1,110 @@
+package cereal
+
+import (
+	"crypto/ecdh"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// FromPEM returns an encryptor for the RSA or X25519 keys in PEM data.
+//
+// Supported blocks are "RSA PRIVATE KEY" (PKCS#1), "PRIVATE KEY" (PKCS#8),
+// "RSA PUBLIC KEY" (PKCS#1), and "PUBLIC KEY" (SPKI). The data may hold one
+// private key, one public key, or a matching pair. A public key alone gives
+// an encrypt-only encryptor. Encrypted PEM blocks are not supported.
+//
+// All errors wrap ErrInvalidKey.
+func FromPEM(data []byte) (Encryptor, error) {
+	var pub, priv any
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		key, private, err := parsePEMBlock(block)
+		if private {
+			zeroize(block.Bytes)
+		}
+		if err != nil {
+			return nil, err
+		}
+		slot := &pub
+		if private {
+			slot = &priv
+		}
+		if *slot != nil {
+			return nil, fmt.Errorf("%w: pem: more than one %s", ErrInvalidKey, block.Type)
+		}
+		*slot = key
+	}
+
+	if pub == nil && priv == nil {
+		return nil, fmt.Errorf("%w: pem: no key found", ErrInvalidKey)
+	}
+	return encryptorForKeys(pub, priv)
+}
+
+// parsePEMBlock parses one PEM block, reporting whether it holds a private
+// key, even on error, so the caller knows to zeroize the block.
+func parsePEMBlock(block *pem.Block) (key any, private bool, err error) {
+	if _, ok := block.Headers["DEK-Info"]; ok {
+		return nil, false, fmt.Errorf("%w: pem: encrypted keys are not supported", ErrInvalidKey)
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		private = true
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		private = true
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, false, fmt.Errorf("%w: pem: unsupported block type %q", ErrInvalidKey, block.Type)
+	}
+	if err != nil {
+		return nil, private, fmt.Errorf("%w: pem: %w", ErrInvalidKey, err)
+	}
+	return key, private, nil
+}
+
+// encryptorForKeys builds the encryptor for a parsed public and/or private
+// key. Either may be nil; both must be the same key type.
+func encryptorForKeys(pub, priv any) (Encryptor, error) {
+	switch k := priv.(type) {
+	case nil:
+	case *rsa.PrivateKey:
+		if pub == nil {
+			return RSA(&k.PublicKey, k), nil
+		}
+		p, ok := pub.(*rsa.PublicKey)
+		if !ok || !p.Equal(&k.PublicKey) {
+			return nil, fmt.Errorf("%w: public key does not match private key", ErrInvalidKey)
+		}
+		return RSA(p, k), nil
+	case *ecdh.PrivateKey:
+		p, _ := pub.(*ecdh.PublicKey)
+		if pub != nil && p == nil {
+			return nil, fmt.Errorf("%w: public key does not match private key", ErrInvalidKey)
+		}
+		return X25519(p, k)
+	default:
+		return nil, fmt.Errorf("%w: unsupported private key type %T", ErrInvalidKey, priv)
+	}
+
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return RSA(k, nil), nil
+	case *ecdh.PublicKey:
+		return X25519(k, nil)
+	default:
+		return nil, fmt.Errorf("%w: unsupported public key type %T", ErrInvalidKey, pub)
+	}
+}
@@ -0,0 +1,107 @@
+package cereal
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+)
+
+func pemBlock(typ string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+}
+
+func TestFromPEM_RSA(t *testing.T) {
+	priv, _ := rsa.GenerateKey(rand.Reader, 2048)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(priv)
+	spki, _ := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		canDecrypt bool
+	}{
+		{"PKCS#1 private", pemBlock("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(priv)), true},
+		{"PKCS#8 private", pemBlock("PRIVATE KEY", pkcs8), true},
+		{"PKCS#1 public", pemBlock("RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&priv.PublicKey)), false},
+		{"SPKI public", pemBlock("PUBLIC KEY", spki), false},
+		{"pair", append(pemBlock("PUBLIC KEY", spki), pemBlock("PRIVATE KEY", pkcs8)...), true},
+	}
+
+	reference := RSA(&priv.PublicKey, priv)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := FromPEM(tt.data)
+			if err != nil {
+				t.Fatalf("FromPEM() error: %v", err)
+			}
+			if kc := enc.(KeyCapabilities); kc.CanDecrypt() != tt.canDecrypt || !kc.CanEncrypt() {
+				t.Errorf("CanDecrypt() = %v, want %v", kc.CanDecrypt(), tt.canDecrypt)
+			}
+
+			ct, err := enc.Encrypt([]byte("secret"))
+			if err != nil {
+				t.Fatalf("Encrypt() error: %v", err)
+			}
+			if pt, err := reference.Decrypt(ct); err != nil || string(pt) != "secret" {
+				t.Errorf("Decrypt() = %q, %v", pt, err)
+			}
+		})
+	}
+}
+
+func TestFromPEM_X25519(t *testing.T) {
+	priv, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(priv)
+	spki, _ := x509.MarshalPKIXPublicKey(priv.PublicKey())
+
+	writer, err := FromPEM(pemBlock("PUBLIC KEY", spki))
+	if err != nil {
+		t.Fatalf("FromPEM(public) error: %v", err)
+	}
+	reader, err := FromPEM(pemBlock("PRIVATE KEY", pkcs8))
+	if err != nil {
+		t.Fatalf("FromPEM(private) error: %v", err)
+	}
+
+	ct, _ := writer.Encrypt([]byte("secret"))
+	if pt, err := reader.Decrypt(ct); err != nil || string(pt) != "secret" {
+		t.Errorf("Decrypt() = %q, %v", pt, err)
+	}
+}
+
+func TestFromPEM_Invalid(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	otherSPKI, _ := x509.MarshalPKIXPublicKey(&otherKey.PublicKey)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	edPKCS8, _ := x509.MarshalPKCS8PrivateKey(edKey)
+
+	tests := map[string][]byte{
+		"empty":       nil,
+		"not pem":     []byte("not a key"),
+		"certificate": pemBlock("CERTIFICATE", []byte{1, 2, 3}),
+		"corrupt":     pemBlock("PRIVATE KEY", []byte{1, 2, 3}),
+		"ed25519":     pemBlock("PRIVATE KEY", edPKCS8),
+		"mismatch": append(pemBlock("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)),
+			pemBlock("PUBLIC KEY", otherSPKI)...),
+		"two private": append(pemBlock("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)),
+			pemBlock("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(otherKey))...),
+		"encrypted": pem.EncodeToMemory(&pem.Block{
+			Type:    "RSA PRIVATE KEY",
+			Headers: map[string]string{"Proc-Type": "4,ENCRYPTED", "DEK-Info": "AES-256-CBC,00"},
+			Bytes:   []byte{1, 2, 3},
+		}),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := FromPEM(data); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("expected ErrInvalidKey, got %v", err)
+			}
+		})
+	}
+}
@@ -2,10 +2,13 @@ package cereal
 
 import (
 	"context"
-	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/zoobzio/sentinel"
@@ -18,37 +21,38 @@ func init() {
 	sentinel.Tag("store.encrypt")
 	sentinel.Tag("send.mask")
 	sentinel.Tag("send.redact")
+	sentinel.Tag("cereal")
 }
 
 // Processor provides context-aware serialization with field transformation.
 // Use Receive/Load for ingress and Store/Send for egress.
 //
 // Processors are safe for concurrent use. Configuration methods (SetEncryptor,
-// SetHasher, SetMasker) may be called at any time to update or rotate keys.
+// SetHasher, SetMasker, and their Remove counterparts) may be called at any
+// time to update or rotate keys. Each publishes a new configuration snapshot;
+// operations already running finish with the snapshot they started with, so
+// neither waits for the other.
 //
-// Validation occurs automatically on first operation. Configure all required
-// handlers before the first call to Receive, Load, Store, or Send.
+// Validation occurs automatically before the first operation, and again
+// before the next operation after any handler is set or removed.
 type Processor[T Cloner[T]] struct {
-	codec Codec
+	// Current configuration, replaced as a whole by configuration methods
+	config atomic.Pointer[processorConfig]
+	mu     sync.Mutex // serializes configuration changes
 
-	// Mutable configuration protected by mu
-	mu         sync.RWMutex
-	encryptors map[EncryptAlgo]Encryptor
-	hashers    map[HashAlgo]Hasher
-	maskers    map[MaskType]Masker
-
-	// Validation state (runs once on first operation)
-	validateOnce sync.Once
-	validateErr  error
+	// Held shared by operations so Close can wait for them
+	inflight sync.RWMutex
 
 	// Per-context field plans (immutable after construction)
 	receivePlans receivePlan
 	loadPlans    loadPlan
 	storePlans   storePlan
 	sendPlans    sendPlan
+	subjectPlan  *processorFieldPlan
 
 	// Type metadata
 	typeName string
+	typePath string // package-qualified type name, for per-field keys
 }
 
 // receivePlan holds field plans for receive context actions.
@@ -74,15 +78,23 @@ type sendPlan struct {
 
 // processorFieldPlan describes how to transform a single field.
 type processorFieldPlan struct {
-	index      []int  // reflect.Value.FieldByIndex access path
-	name       string // field name for error messages
-	tagVal     string // tag value (e.g., "aes", "argon2", "ssn", "***")
-	isBytes    bool   // true if field is []byte, false if string
-	ptrIndices []int  // indices where pointer dereference is needed
-	isSlice    bool   // true if field is []string
-	isMap      bool   // true if field is map[K]string
+	index      []int           // reflect.Value.FieldByIndex access path
+	name       string          // field name for error messages
+	tagVal     string          // tag value (e.g., "aes", "argon2", "ssn", "***")
+	isBytes    bool            // true if field is []byte, false if string
+	ptrIndices []int           // indices where pointer dereference is needed
+	isSlice    bool            // true if field is []string
+	isMap      bool            // true if field is map[K]string
+	opts       *encryptOptions // encrypt/decrypt tag options (e.g., "aes,pad=pow2"), nil if none
+	isReader   bool            // true if field is io.Reader or io.ReadCloser (streamed)
 }
 
+// Stream field types encrypted with a StreamEncryptor.
+var (
+	readerType     = reflect.TypeOf((*io.Reader)(nil)).Elem()
+	readCloserType = reflect.TypeOf((*io.ReadCloser)(nil)).Elem()
+)
+
 // NewProcessor creates a new Processor for type T.
 //
 // The processor is created with builtin hashers and maskers. Encryptors must
@@ -101,79 +113,227 @@ func NewProcessor[T Cloner[T]]() (*Processor[T], error) {
 	}
 
 	p := &Processor[T]{
-		encryptors:   make(map[EncryptAlgo]Encryptor),
-		hashers:      builtinHashers(),
-		maskers:      builtinMaskers(),
 		typeName:     plans.typeName,
+		typePath:     qualifiedTypeName[T](),
 		receivePlans: plans.receive,
 		loadPlans:    plans.load,
 		storePlans:   plans.store,
 		sendPlans:    plans.send,
+		subjectPlan:  plans.subject,
 	}
+	p.config.Store(newProcessorConfig())
 
-	contentType := ""
-	if p.codec != nil {
-		contentType = p.codec.ContentType()
-	}
-	emitProcessorCreated(context.Background(), contentType, plans.typeName)
+	emitProcessorCreated(context.Background(), p.config.Load().contentType(), plans.typeName)
 	return p, nil
 }
 
+// update publishes a modified copy of the configuration. Operations already
+// running keep the snapshot they started with.
+func (p *Processor[T]) update(modify func(c *processorConfig)) *Processor[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	next := p.config.Load().clone()
+	modify(next)
+	p.config.Store(next)
+	return p
+}
+
 // SetCodec registers a codec for marshal/unmarshal operations.
 // Required for Decode, Read, Write, and Encode methods.
 // Returns the processor for chaining. Safe for concurrent use.
 func (p *Processor[T]) SetCodec(codec Codec) *Processor[T] {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.codec = codec
-	return p
+	return p.update(func(c *processorConfig) {
+		c.codec = codec
+	})
 }
 
-// SetEncryptor registers an encryptor for the given algorithm.
+// SetCiphertextEncoding sets how ciphertext is encoded in string fields.
+// The default is EncodingStd; a field's enc tag option overrides it.
+// Decryption detects the encoding, so changing it does not affect reading
+// existing data: raw EncodingBinary values are still read while the codec
+// implements BinaryCodec. Unknown encodings fall back to EncodingStd.
+// Returns the processor for chaining. Safe for concurrent use.
+func (p *Processor[T]) SetCiphertextEncoding(enc CiphertextEncoding) *Processor[T] {
+	if !IsValidCiphertextEncoding(enc) {
+		enc = EncodingStd
+	}
+	return p.update(func(c *processorConfig) {
+		c.encoding = enc
+	})
+}
+
+// SetEncryptor registers an encryptor for the given algorithm. A nil
+// encryptor removes the registration, as RemoveEncryptor does.
+// The next operation re-validates the processor.
 // Returns the processor for chaining. Safe for concurrent use.
 func (p *Processor[T]) SetEncryptor(algo EncryptAlgo, enc Encryptor) *Processor[T] {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.encryptors[algo] = enc
-	return p
+	return p.update(func(c *processorConfig) {
+		if enc == nil {
+			delete(c.encryptors, algo)
+		} else {
+			c.encryptors[algo] = enc
+		}
+		c.invalidate()
+	})
 }
 
-// SetHasher registers a hasher for the given algorithm.
+// SetHasher registers a hasher for the given algorithm. A nil hasher
+// removes the registration, as RemoveHasher does.
+// The next operation re-validates the processor.
 // Returns the processor for chaining. Safe for concurrent use.
 func (p *Processor[T]) SetHasher(algo HashAlgo, h Hasher) *Processor[T] {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.hashers[algo] = h
-	return p
+	return p.update(func(c *processorConfig) {
+		if h == nil {
+			delete(c.hashers, algo)
+		} else {
+			c.hashers[algo] = h
+		}
+		c.invalidate()
+	})
 }
 
-// SetMasker registers a masker for the given type.
+// SetMasker registers a masker for the given type. A nil masker removes
+// the registration, as RemoveMasker does.
+// The next operation re-validates the processor.
 // Returns the processor for chaining. Safe for concurrent use.
 func (p *Processor[T]) SetMasker(mt MaskType, m Masker) *Processor[T] {
+	return p.update(func(c *processorConfig) {
+		if m == nil {
+			delete(c.maskers, mt)
+		} else {
+			c.maskers[mt] = m
+		}
+		c.invalidate()
+	})
+}
+
+// RemoveEncryptor unregisters the encryptor for the given algorithm. It does
+// not destroy the encryptor. The next operation re-validates the processor,
+// failing if a field still needs the algorithm.
+// Returns the processor for chaining. Safe for concurrent use.
+func (p *Processor[T]) RemoveEncryptor(algo EncryptAlgo) *Processor[T] {
+	return p.SetEncryptor(algo, nil)
+}
+
+// RemoveHasher unregisters the hasher for the given algorithm, including a
+// builtin one. The next operation re-validates the processor.
+// Returns the processor for chaining. Safe for concurrent use.
+func (p *Processor[T]) RemoveHasher(algo HashAlgo) *Processor[T] {
+	return p.SetHasher(algo, nil)
+}
+
+// RemoveMasker unregisters the masker for the given type, including a
+// builtin one. The next operation re-validates the processor.
+// Returns the processor for chaining. Safe for concurrent use.
+func (p *Processor[T]) RemoveMasker(mt MaskType) *Processor[T] {
+	return p.SetMasker(mt, nil)
+}
+
+// SetSelfTest enables an encrypt/decrypt round trip through each encryptor
+// during validation, so a wrong or partial key fails Validate instead of the
+// first Store or Load. See SelfTest; validation runs it with a background
+// context, and again whenever an encryptor changes. Fields the self-test
+// cannot exercise, because their encryptor needs a tenant or subject or
+// cannot encrypt a probe, are skipped; call SelfTest to see them. A failure
+// wrapping ErrEncrypt is not cached, so the next operation retries it.
+// Returns the processor for chaining. Safe for concurrent use.
+func (p *Processor[T]) SetSelfTest(enabled bool) *Processor[T] {
+	return p.update(func(c *processorConfig) {
+		c.selfTest = enabled
+		c.invalidate()
+	})
+}
+
+// SetClock replaces the clock used for encryption TTLs (ttl tag option).
+// Intended for tests; defaults to time.Now, and nil restores it.
+// Returns the processor for chaining. Safe for concurrent use.
+func (p *Processor[T]) SetClock(now func() time.Time) *Processor[T] {
+	if now == nil {
+		now = time.Now
+	}
+	return p.update(func(c *processorConfig) {
+		c.now = now
+	})
+}
+
+// Close destroys the key material of registered encryptors and hashers that
+// implement Destroyer, then drops every registered handler. It waits for
+// in-flight operations; later operations return ErrClosed.
+// Close is idempotent and always returns nil.
+//
+// The processor does not track who else holds a handler: an encryptor or
+// hasher registered with several processors is destroyed by the first Close
+// and fails with ErrClosed everywhere else. Close only the processor that
+// owns its handlers, or destroy shared handlers yourself.
+func (p *Processor[T]) Close() error {
+	p.inflight.Lock()
+	defer p.inflight.Unlock()
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	p.maskers[mt] = m
-	return p
+
+	cfg := p.config.Load()
+	if cfg.closed {
+		return nil
+	}
+	p.config.Store(&processorConfig{
+		encryptors: make(map[EncryptAlgo]Encryptor),
+		hashers:    make(map[HashAlgo]Hasher),
+		maskers:    make(map[MaskType]Masker),
+		now:        time.Now,
+		encoding:   EncodingStd,
+		closed:     true,
+		validation: &validation{},
+	})
+
+	for _, enc := range cfg.encryptors {
+		if d, ok := enc.(Destroyer); ok {
+			d.Destroy()
+		}
+	}
+	for _, h := range cfg.hashers {
+		if d, ok := h.(Destroyer); ok {
+			d.Destroy()
+		}
+	}
+	return nil
 }
 
 // Validate checks that all required capabilities are configured.
 // Returns an error if any field's required encryptor, hasher, or masker
 // is not registered.
 //
-// Validation also runs automatically on first operation. Calling Validate
-// explicitly allows catching configuration errors at startup.
+// Validation also runs automatically before the first operation and after
+// any handler change. Calling Validate explicitly allows catching
+// configuration errors at startup.
 func (p *Processor[T]) Validate() error {
-	return p.ensureValidated()
+	p.inflight.RLock()
+	defer p.inflight.RUnlock()
+	_, err := p.validatedConfig()
+	return err
 }
 
-// ensureValidated runs validation once and caches the result.
-func (p *Processor[T]) ensureValidated() error {
-	p.validateOnce.Do(func() {
-		p.mu.RLock()
-		defer p.mu.RUnlock()
-		p.validateErr = p.validateCapabilities()
+// validatedConfig returns the current configuration once it has been
+// validated, validating it first if its handlers are new. Callers that use
+// the handlers must hold p.inflight.
+func (p *Processor[T]) validatedConfig() (*processorConfig, error) {
+	cfg := p.config.Load()
+	if cfg.closed {
+		return nil, ErrClosed
+	}
+
+	err := cfg.validation.run(func() error {
+		if err := p.validateCapabilities(cfg); err != nil {
+			return err
+		}
+		if cfg.selfTest {
+			return p.runSelfTest(context.Background(), cfg, true)
+		}
+		return nil
 	})
-	return p.validateErr
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
 }
 
 // buildFieldPlans creates field plans for type T by scanning struct tags.
@@ -187,9 +347,53 @@ func buildFieldPlans[T Cloner[T]]() (*typeFieldPlans, error) {
 		return nil, err
 	}
 
+	// Override interfaces handle encryption themselves, so tags are not paired
+	var zero T
+	_, hasEncryptable := any(&zero).(Encryptable)
+	_, hasDecryptable := any(&zero).(Decryptable)
+	if !hasEncryptable && !hasDecryptable {
+		if err := checkEncryptPairing(plans); err != nil {
+			return nil, err
+		}
+	}
+
 	return plans, nil
 }
 
+// checkEncryptPairing requires every store.encrypt field to have a
+// load.decrypt tag with the same algorithm and frame options, and the
+// reverse. A load.decrypt tag without frame options inherits them, so it
+// may omit them. The writeonly and readonly tag options opt a field out.
+func checkEncryptPairing(plans *typeFieldPlans) error {
+	decrypts := make(map[string]processorFieldPlan, len(plans.load.decryptFields))
+	for _, plan := range plans.load.decryptFields {
+		decrypts[plan.name] = plan
+	}
+
+	encrypts := make(map[string]bool, len(plans.store.encryptFields))
+	for _, plan := range plans.store.encryptFields {
+		encrypts[plan.name] = true
+		dec, ok := decrypts[plan.name]
+		if !ok && (plan.opts == nil || !plan.opts.writeOnly) {
+			return newConfigError(fmt.Errorf("%w: no load.decrypt", ErrUnpairedTag), plan.tagVal, plan.name)
+		}
+		if ok && dec.tagVal != plan.tagVal {
+			return newConfigError(fmt.Errorf("%w: load.decrypt uses %q", ErrUnpairedTag, dec.tagVal), plan.tagVal, plan.name)
+		}
+		if ok && !dec.opts.sameFrame(plan.opts) {
+			return newConfigError(fmt.Errorf("%w: frame options differ from load.decrypt", ErrUnpairedTag), plan.tagVal, plan.name)
+		}
+	}
+
+	for _, plan := range plans.load.decryptFields {
+		if !encrypts[plan.name] && (plan.opts == nil || !plan.opts.readOnly) {
+			return newConfigError(fmt.Errorf("%w: no store.encrypt", ErrUnpairedTag), plan.tagVal, plan.name)
+		}
+	}
+
+	return nil
+}
+
 // buildFieldPlansRecursive recursively processes fields and nested structs.
 func buildFieldPlansRecursive(plans *typeFieldPlans, spec sentinel.Metadata, parentIndex, ptrIndices []int, namePrefix string) error {
 	for _, field := range spec.Fields {
@@ -230,8 +434,12 @@ func buildFieldPlansRecursive(plans *typeFieldPlans, spec sentinel.Metadata, par
 			field.ReflectType.Elem().Kind() == reflect.String
 		isStringMap := field.ReflectType.Kind() == reflect.Map &&
 			field.ReflectType.Elem().Kind() == reflect.String
+		isReader := field.ReflectType == readerType || field.ReflectType == readCloserType
 
-		if !isString && !isBytes && !isStringSlice && !isStringMap {
+		if !isString && !isBytes && !isStringSlice && !isStringMap && !isReader {
+			if _, ok := field.Tags["cereal"]; ok {
+				return &ConfigError{Err: ErrInvalidTag, Algorithm: field.Tags["cereal"], Field: fullName}
+			}
 			continue
 		}
 
@@ -242,6 +450,29 @@ func buildFieldPlansRecursive(plans *typeFieldPlans, spec sentinel.Metadata, par
 			ptrIndices: ptrIndices,
 			isSlice:    isStringSlice,
 			isMap:      isStringMap,
+			isReader:   isReader,
+		}
+
+		// Streamed fields support only encryption, without tag options
+		if isReader {
+			for _, tag := range []string{"receive.hash", "send.mask", "send.redact", "cereal"} {
+				if val, ok := field.Tags[tag]; ok {
+					return &ConfigError{Err: ErrInvalidTag, Algorithm: val, Field: fullName}
+				}
+			}
+		}
+
+		// Subject field: its value selects the per-subject key and must stay readable
+		if val, ok := field.Tags["cereal"]; ok {
+			if val != "subject" || !isString || plans.subject != nil {
+				return &ConfigError{Err: ErrInvalidTag, Algorithm: val, Field: fullName}
+			}
+			if _, encrypted := field.Tags["store.encrypt"]; encrypted {
+				return &ConfigError{Err: ErrInvalidTag, Algorithm: val, Field: fullName}
+			}
+			plan := basePlan
+			plan.tagVal = val
+			plans.subject = &plan
 		}
 
 		// Check for compound tags
@@ -254,22 +485,44 @@ func buildFieldPlansRecursive(plans *typeFieldPlans, spec sentinel.Metadata, par
 			plans.receive.hashFields = append(plans.receive.hashFields, plan)
 		}
 
-		if val, ok := field.Tags["load.decrypt"]; ok {
-			if !IsValidEncryptAlgo(EncryptAlgo(val)) {
+		// store.encrypt is parsed first so load.decrypt can inherit its options
+		// The writeonly/readonly opt-outs only make sense when the other tag is absent
+		_, hasStore := field.Tags["store.encrypt"]
+		_, hasLoad := field.Tags["load.decrypt"]
+		var storeOpts *encryptOptions
+		if val, ok := field.Tags["store.encrypt"]; ok {
+			algo, opts, err := parseEncryptTag(val)
+			if err != nil || !IsValidEncryptAlgo(EncryptAlgo(algo)) || (isReader && opts.transforms()) ||
+				(opts != nil && (opts.readOnly || (opts.writeOnly && hasLoad))) {
 				return &ConfigError{Err: ErrInvalidTag, Algorithm: val, Field: fullName}
 			}
 			plan := basePlan
-			plan.tagVal = val
-			plans.load.decryptFields = append(plans.load.decryptFields, plan)
+			plan.tagVal = algo
+			plan.opts = opts
+			storeOpts = opts
+			plans.store.encryptFields = append(plans.store.encryptFields, plan)
 		}
 
-		if val, ok := field.Tags["store.encrypt"]; ok {
-			if !IsValidEncryptAlgo(EncryptAlgo(val)) {
+		if val, ok := field.Tags["load.decrypt"]; ok {
+			algo, opts, err := parseEncryptTag(val)
+			if err != nil || !IsValidEncryptAlgo(EncryptAlgo(algo)) || (isReader && opts.transforms()) ||
+				(opts != nil && (opts.writeOnly || (opts.readOnly && hasStore))) {
 				return &ConfigError{Err: ErrInvalidTag, Algorithm: val, Field: fullName}
 			}
+			if storeOpts.framed() && !opts.framed() {
+				// Inherit the frame, keeping any encoding set on load.decrypt.
+				merged := *storeOpts
+				if opts != nil && opts.encoding != "" {
+					merged.encoding = opts.encoding
+				}
+				opts = &merged
+			} else if opts == nil {
+				opts = storeOpts
+			}
 			plan := basePlan
-			plan.tagVal = val
-			plans.store.encryptFields = append(plans.store.encryptFields, plan)
+			plan.tagVal = algo
+			plan.opts = opts
+			plans.load.decryptFields = append(plans.load.decryptFields, plan)
 		}
 
 		if val, ok := field.Tags["send.mask"]; ok {
@@ -352,6 +605,7 @@ func parseContextTags(tag reflect.StructTag) map[string]string {
 		"store.encrypt",
 		"send.mask",
 		"send.redact",
+		"cereal",
 	}
 
 	for _, ca := range contextActions {
@@ -365,7 +619,7 @@ func parseContextTags(tag reflect.StructTag) map[string]string {
 
 // validateCapabilities ensures all required capabilities are registered.
 // Skips validation for transform types where the type implements override interfaces.
-func (p *Processor[T]) validateCapabilities() error {
+func (p *Processor[T]) validateCapabilities(cfg *processorConfig) error {
 	// Check which override interfaces are implemented
 	var zero T
 	_, hasHashable := any(&zero).(Hashable)
@@ -377,7 +631,7 @@ func (p *Processor[T]) validateCapabilities() error {
 	if !hasHashable {
 		for _, plan := range p.receivePlans.hashFields {
 			algo := HashAlgo(plan.tagVal)
-			if _, ok := p.hashers[algo]; !ok {
+			if _, ok := cfg.hashers[algo]; !ok {
 				return newConfigError(ErrMissingHasher, plan.tagVal, plan.name)
 			}
 		}
@@ -387,9 +641,16 @@ func (p *Processor[T]) validateCapabilities() error {
 	if !hasDecryptable {
 		for _, plan := range p.loadPlans.decryptFields {
 			algo := EncryptAlgo(plan.tagVal)
-			if _, ok := p.encryptors[algo]; !ok {
+			enc, ok := cfg.encryptors[algo]
+			if !ok {
 				return newConfigError(ErrMissingEncryptor, plan.tagVal, plan.name)
 			}
+			if kc, ok := enc.(KeyCapabilities); ok && !kc.CanDecrypt() {
+				return newConfigError(ErrDecryptUnsupported, plan.tagVal, plan.name)
+			}
+			if _, ok := enc.(StreamEncryptor); plan.isReader && !ok {
+				return newConfigError(ErrDecryptUnsupported, plan.tagVal, plan.name)
+			}
 		}
 	}
 
@@ -397,9 +658,16 @@ func (p *Processor[T]) validateCapabilities() error {
 	if !hasEncryptable {
 		for _, plan := range p.storePlans.encryptFields {
 			algo := EncryptAlgo(plan.tagVal)
-			if _, ok := p.encryptors[algo]; !ok {
+			enc, ok := cfg.encryptors[algo]
+			if !ok {
 				return newConfigError(ErrMissingEncryptor, plan.tagVal, plan.name)
 			}
+			if kc, ok := enc.(KeyCapabilities); ok && !kc.CanEncrypt() {
+				return newConfigError(ErrEncryptUnsupported, plan.tagVal, plan.name)
+			}
+			if _, ok := enc.(StreamEncryptor); plan.isReader && !ok {
+				return newConfigError(ErrEncryptUnsupported, plan.tagVal, plan.name)
+			}
 		}
 	}
 
@@ -407,7 +675,7 @@ func (p *Processor[T]) validateCapabilities() error {
 	if !hasMaskable {
 		for _, plan := range p.sendPlans.maskFields {
 			mt := MaskType(plan.tagVal)
-			if _, ok := p.maskers[mt]; !ok {
+			if _, ok := cfg.maskers[mt]; !ok {
 				return newConfigError(ErrMissingMasker, plan.tagVal, plan.name)
 			}
 		}
@@ -423,12 +691,16 @@ func (p *Processor[T]) validateCapabilities() error {
 //nolint:dupl // Intentional parallel structure with Load for boundary operations
 func (p *Processor[T]) Receive(ctx context.Context, obj T) (T, error) {
 	var zero T
-	if err := p.ensureValidated(); err != nil {
+	p.inflight.RLock()
+	defer p.inflight.RUnlock()
+
+	cfg, err := p.validatedConfig()
+	if err != nil {
 		return zero, err
 	}
 
 	start := time.Now()
-	contentType := p.contentType()
+	contentType := cfg.contentType()
 	emitReceiveStart(ctx, contentType, p.typeName)
 
 	var retErr error
@@ -439,12 +711,9 @@ func (p *Processor[T]) Receive(ctx context.Context, obj T) (T, error) {
 
 	clone := obj.Clone()
 
-	p.mu.RLock()
-	defer p.mu.RUnlock()
-
 	// Check for override interface
 	if h, ok := any(&clone).(Hashable); ok {
-		if err := h.Hash(p.hashers); err != nil {
+		if err := h.Hash(cfg.hashers); err != nil {
 			retErr = fmt.Errorf("hash: %w", err)
 			return zero, retErr
 		}
@@ -452,7 +721,7 @@ func (p *Processor[T]) Receive(ctx context.Context, obj T) (T, error) {
 	}
 
 	// Apply hash actions via reflection
-	if err := p.applyHash(&clone); err != nil {
+	if err := p.applyHash(ctx, cfg, &clone); err != nil {
 		retErr = err
 		return zero, retErr
 	}
@@ -466,18 +735,11 @@ func (p *Processor[T]) Receive(ctx context.Context, obj T) (T, error) {
 //
 //nolint:dupl // Intentional parallel structure with Read for boundary operations
 func (p *Processor[T]) Decode(ctx context.Context, data []byte) (*T, error) {
-	if err := p.ensureValidated(); err != nil {
+	codec, err := p.validatedCodec()
+	if err != nil {
 		return nil, err
 	}
 
-	p.mu.RLock()
-	codec := p.codec
-	p.mu.RUnlock()
-
-	if codec == nil {
-		return nil, &ConfigError{Err: ErrMissingCodec}
-	}
-
 	var obj T
 	if err := codec.Unmarshal(data, &obj); err != nil {
 		return nil, newCodecError(ErrUnmarshal, err)
@@ -497,12 +759,16 @@ func (p *Processor[T]) Decode(ctx context.Context, data []byte) (*T, error) {
 //nolint:dupl // Intentional parallel structure with Receive for boundary operations
 func (p *Processor[T]) Load(ctx context.Context, obj T) (T, error) {
 	var zero T
-	if err := p.ensureValidated(); err != nil {
+	p.inflight.RLock()
+	defer p.inflight.RUnlock()
+
+	cfg, err := p.validatedConfig()
+	if err != nil {
 		return zero, err
 	}
 
 	start := time.Now()
-	contentType := p.contentType()
+	contentType := cfg.contentType()
 	emitLoadStart(ctx, contentType, p.typeName)
 
 	var retErr error
@@ -513,12 +779,9 @@ func (p *Processor[T]) Load(ctx context.Context, obj T) (T, error) {
 
 	clone := obj.Clone()
 
-	p.mu.RLock()
-	defer p.mu.RUnlock()
-
 	// Check for override interface
 	if d, ok := any(&clone).(Decryptable); ok {
-		if err := d.Decrypt(p.encryptors); err != nil {
+		if err := d.Decrypt(cfg.encryptors); err != nil {
 			retErr = fmt.Errorf("decrypt: %w", err)
 			return zero, retErr
 		}
@@ -526,7 +789,7 @@ func (p *Processor[T]) Load(ctx context.Context, obj T) (T, error) {
 	}
 
 	// Apply decrypt actions via reflection
-	if err := p.applyDecrypt(&clone); err != nil {
+	if err := p.applyDecrypt(p.subjectContext(ctx, &clone), cfg, &clone); err != nil {
 		retErr = err
 		return zero, retErr
 	}
@@ -540,18 +803,11 @@ func (p *Processor[T]) Load(ctx context.Context, obj T) (T, error) {
 //
 //nolint:dupl // Intentional parallel structure with Decode for boundary operations
 func (p *Processor[T]) Read(ctx context.Context, data []byte) (*T, error) {
-	if err := p.ensureValidated(); err != nil {
+	codec, err := p.validatedCodec()
+	if err != nil {
 		return nil, err
 	}
 
-	p.mu.RLock()
-	codec := p.codec
-	p.mu.RUnlock()
-
-	if codec == nil {
-		return nil, &ConfigError{Err: ErrMissingCodec}
-	}
-
 	var obj T
 	if err := codec.Unmarshal(data, &obj); err != nil {
 		return nil, newCodecError(ErrUnmarshal, err)
@@ -569,12 +825,16 @@ func (p *Processor[T]) Read(ctx context.Context, data []byte) (*T, error) {
 // Use for data going to storage (database, cache).
 func (p *Processor[T]) Store(ctx context.Context, obj T) (T, error) {
 	var zero T
-	if err := p.ensureValidated(); err != nil {
+	p.inflight.RLock()
+	defer p.inflight.RUnlock()
+
+	cfg, err := p.validatedConfig()
+	if err != nil {
 		return zero, err
 	}
 
 	start := time.Now()
-	contentType := p.contentType()
+	contentType := cfg.contentType()
 	emitStoreStart(ctx, contentType, p.typeName)
 
 	var retErr error
@@ -586,12 +846,9 @@ func (p *Processor[T]) Store(ctx context.Context, obj T) (T, error) {
 	// Clone to avoid mutating original
 	clone := obj.Clone()
 
-	p.mu.RLock()
-	defer p.mu.RUnlock()
-
 	// Check for override interface
 	if e, ok := any(&clone).(Encryptable); ok {
-		if err := e.Encrypt(p.encryptors); err != nil {
+		if err := e.Encrypt(cfg.encryptors); err != nil {
 			retErr = fmt.Errorf("encrypt: %w", err)
 			return zero, retErr
 		}
@@ -599,7 +856,7 @@ func (p *Processor[T]) Store(ctx context.Context, obj T) (T, error) {
 	}
 
 	// Apply encrypt actions via reflection
-	if err := p.applyEncrypt(&clone); err != nil {
+	if err := p.applyEncrypt(p.subjectContext(ctx, &clone), cfg, &clone); err != nil {
 		retErr = err
 		return zero, retErr
 	}
@@ -611,18 +868,11 @@ func (p *Processor[T]) Store(ctx context.Context, obj T) (T, error) {
 // Requires a codec to be configured via SetCodec.
 // Use for data going to storage (database, cache).
 func (p *Processor[T]) Write(ctx context.Context, obj *T) ([]byte, error) {
-	if err := p.ensureValidated(); err != nil {
+	codec, err := p.validatedCodec()
+	if err != nil {
 		return nil, err
 	}
 
-	p.mu.RLock()
-	codec := p.codec
-	p.mu.RUnlock()
-
-	if codec == nil {
-		return nil, &ConfigError{Err: ErrMissingCodec}
-	}
-
 	if obj == nil {
 		return codec.Marshal(nil)
 	}
@@ -644,12 +894,16 @@ func (p *Processor[T]) Write(ctx context.Context, obj *T) ([]byte, error) {
 // Use for data going to external destinations (API responses, events).
 func (p *Processor[T]) Send(ctx context.Context, obj T) (T, error) {
 	var zero T
-	if err := p.ensureValidated(); err != nil {
+	p.inflight.RLock()
+	defer p.inflight.RUnlock()
+
+	cfg, err := p.validatedConfig()
+	if err != nil {
 		return zero, err
 	}
 
 	start := time.Now()
-	contentType := p.contentType()
+	contentType := cfg.contentType()
 	emitSendStart(ctx, contentType, p.typeName)
 
 	var retErr error
@@ -662,17 +916,14 @@ func (p *Processor[T]) Send(ctx context.Context, obj T) (T, error) {
 	// Clone to avoid mutating original
 	clone := obj.Clone()
 
-	p.mu.RLock()
-	defer p.mu.RUnlock()
-
 	// Apply mask - check for override interface
 	if m, ok := any(&clone).(Maskable); ok {
-		if err := m.Mask(p.maskers); err != nil {
+		if err := m.Mask(cfg.maskers); err != nil {
 			retErr = fmt.Errorf("mask: %w", err)
 			return zero, retErr
 		}
 	} else {
-		if err := p.applyMask(&clone); err != nil {
+		if err := p.applyMask(ctx, cfg, &clone); err != nil {
 			retErr = err
 			return zero, retErr
 		}
@@ -698,18 +949,11 @@ func (p *Processor[T]) Send(ctx context.Context, obj T) (T, error) {
 // Requires a codec to be configured via SetCodec.
 // Use for data going to external destinations (API responses, events).
 func (p *Processor[T]) Encode(ctx context.Context, obj *T) ([]byte, error) {
-	if err := p.ensureValidated(); err != nil {
+	codec, err := p.validatedCodec()
+	if err != nil {
 		return nil, err
 	}
 
-	p.mu.RLock()
-	codec := p.codec
-	p.mu.RUnlock()
-
-	if codec == nil {
-		return nil, &ConfigError{Err: ErrMissingCodec}
-	}
-
 	if obj == nil {
 		return codec.Marshal(nil)
 	}
@@ -726,22 +970,31 @@ func (p *Processor[T]) Encode(ctx context.Context, obj *T) ([]byte, error) {
 	return data, nil
 }
 
-// contentType returns the codec content type or empty string if no codec is set.
-func (p *Processor[T]) contentType() string {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
-	if p.codec != nil {
-		return p.codec.ContentType()
+// validatedCodec validates the configuration and returns its codec.
+func (p *Processor[T]) validatedCodec() (Codec, error) {
+	p.inflight.RLock()
+	defer p.inflight.RUnlock()
+
+	cfg, err := p.validatedConfig()
+	if err != nil {
+		return nil, err
 	}
-	return ""
+	if cfg.codec == nil {
+		return nil, &ConfigError{Err: ErrMissingCodec}
+	}
+	return cfg.codec, nil
 }
 
 // applyHash applies hash transformations via reflection.
-func (p *Processor[T]) applyHash(obj *T) error {
+func (p *Processor[T]) applyHash(ctx context.Context, cfg *processorConfig, obj *T) error {
 	rv := reflect.ValueOf(obj).Elem()
 
 	for _, plan := range p.receivePlans.hashFields {
-		hasher := p.hashers[HashAlgo(plan.tagVal)]
+		hasher, ok := cfg.hashers[HashAlgo(plan.tagVal)]
+		if !ok {
+			// Removed after validation; the next operation re-validates
+			return newConfigError(ErrMissingHasher, plan.tagVal, plan.name)
+		}
 
 		field, ok := p.getField(rv, plan)
 		if !ok {
@@ -753,9 +1006,9 @@ func (p *Processor[T]) applyHash(obj *T) error {
 			for i := 0; i < field.Len(); i++ {
 				elem := field.Index(i)
 				if elem.CanSet() {
-					hashed, err := hasher.Hash([]byte(elem.String()))
+					hashed, err := hashString(ctx, hasher, elem.String())
 					if err != nil {
-						return newTransformError(ErrHash, "hash", fmt.Sprintf("%s[%d]", plan.name, i), err)
+						return newTransformError(hashSentinel(err), "hash", fmt.Sprintf("%s[%d]", plan.name, i), err)
 					}
 					elem.SetString(hashed)
 				}
@@ -768,9 +1021,9 @@ func (p *Processor[T]) applyHash(obj *T) error {
 			iter := field.MapRange()
 			for iter.Next() {
 				k, v := iter.Key(), iter.Value()
-				hashed, err := hasher.Hash([]byte(v.String()))
+				hashed, err := hashString(ctx, hasher, v.String())
 				if err != nil {
-					return newTransformError(ErrHash, "hash", fmt.Sprintf("%s[%v]", plan.name, k.Interface()), err)
+					return newTransformError(hashSentinel(err), "hash", fmt.Sprintf("%s[%v]", plan.name, k.Interface()), err)
 				}
 				field.SetMapIndex(k, reflect.ValueOf(hashed))
 			}
@@ -782,16 +1035,15 @@ func (p *Processor[T]) applyHash(obj *T) error {
 			continue
 		}
 
-		var plaintext []byte
+		var hashed string
+		var err error
 		if plan.isBytes {
-			plaintext = field.Bytes()
+			hashed, err = hashContext(ctx, hasher, field.Bytes())
 		} else {
-			plaintext = []byte(field.String())
+			hashed, err = hashString(ctx, hasher, field.String())
 		}
-
-		hashed, err := hasher.Hash(plaintext)
 		if err != nil {
-			return newTransformError(ErrHash, "hash", plan.name, err)
+			return newTransformError(hashSentinel(err), "hash", plan.name, err)
 		}
 
 		if plan.isBytes {
@@ -804,110 +1056,357 @@ func (p *Processor[T]) applyHash(obj *T) error {
 	return nil
 }
 
+// hashString hashes a copy of s and zeroizes the copy afterwards.
+func hashString(ctx context.Context, hasher Hasher, s string) (string, error) {
+	plaintext := []byte(s)
+	defer zeroize(plaintext)
+	return hashContext(ctx, hasher, plaintext)
+}
+
 // applyDecrypt applies decrypt transformations via reflection.
-func (p *Processor[T]) applyDecrypt(obj *T) error {
+// Values are grouped by algorithm so a BatchEncryptor receives every
+// ciphertext for its algorithm in a single call.
+func (p *Processor[T]) applyDecrypt(ctx context.Context, cfg *processorConfig, obj *T) error {
 	rv := reflect.ValueOf(obj).Elem()
 
-	for _, plan := range p.loadPlans.decryptFields {
-		enc := p.encryptors[EncryptAlgo(plan.tagVal)]
+	now := cfg.now()
 
-		field, ok := p.getField(rv, plan)
+	for _, group := range p.collectEncrypted(rv, p.loadPlans.decryptFields) {
+		enc, ok := cfg.encryptors[group.algo]
 		if !ok {
-			continue
+			// Removed after validation; the next operation re-validates
+			return newConfigError(ErrMissingEncryptor, string(group.algo), group.fieldNames())
 		}
 
-		// Handle slice of strings
-		if plan.isSlice {
-			for i := 0; i < field.Len(); i++ {
-				elem := field.Index(i)
-				if elem.CanSet() {
-					ciphertext, err := base64.StdEncoding.DecodeString(elem.String())
-					if err != nil {
-						return newTransformError(ErrDecrypt, "decrypt", fmt.Sprintf("%s[%d]", plan.name, i), err)
-					}
-					plaintext, err := enc.Decrypt(ciphertext)
-					if err != nil {
-						return newTransformError(ErrDecrypt, "decrypt", fmt.Sprintf("%s[%d]", plan.name, i), err)
-					}
-					elem.SetString(string(plaintext))
+		ciphertexts := make([][]byte, len(group.values))
+		for i, v := range group.values {
+			if !v.text {
+				ciphertexts[i] = v.value
+				continue
+			}
+			decoded, err := decodeCiphertext(string(v.value), cfg.binaryStrings())
+			if err != nil {
+				return newTransformError(ErrDecrypt, "decrypt", v.name, err)
+			}
+			ciphertexts[i] = decoded
+		}
+
+		if be, ok := enc.(BatchEncryptor); ok {
+			plaintexts, err := be.DecryptBatch(p.batchContext(ctx, group.values), ciphertexts)
+			if err == nil && len(plaintexts) != len(ciphertexts) {
+				err = fmt.Errorf("batch returned %d results for %d values", len(plaintexts), len(ciphertexts))
+			}
+			if err != nil {
+				return newTransformError(decryptSentinel(err), "decrypt", group.fieldNames(), err)
+			}
+			for i, v := range group.values {
+				if err := v.setPlaintext(plaintexts[i], now); err != nil {
+					return newTransformError(decryptSentinel(err), "decrypt", v.name, err)
 				}
 			}
 			continue
 		}
 
-		// Handle map of strings
-		if plan.isMap {
-			iter := field.MapRange()
-			for iter.Next() {
-				k, v := iter.Key(), iter.Value()
-				ciphertext, err := base64.StdEncoding.DecodeString(v.String())
-				if err != nil {
-					return newTransformError(ErrDecrypt, "decrypt", fmt.Sprintf("%s[%v]", plan.name, k.Interface()), err)
-				}
-				plaintext, err := enc.Decrypt(ciphertext)
-				if err != nil {
-					return newTransformError(ErrDecrypt, "decrypt", fmt.Sprintf("%s[%v]", plan.name, k.Interface()), err)
+		for i, v := range group.values {
+			plaintext, err := decryptContext(p.fieldContext(ctx, v.plan), enc, ciphertexts[i])
+			if err != nil {
+				return newTransformError(decryptSentinel(err), "decrypt", v.name, err)
+			}
+			if err := v.setPlaintext(plaintext, now); err != nil {
+				return newTransformError(decryptSentinel(err), "decrypt", v.name, err)
+			}
+		}
+	}
+
+	return p.applyStreams(ctx, cfg, rv, p.loadPlans.decryptFields, false)
+}
+
+// applyEncrypt applies encrypt transformations via reflection.
+// Values are grouped by algorithm so a BatchEncryptor receives every
+// plaintext for its algorithm in a single call.
+func (p *Processor[T]) applyEncrypt(ctx context.Context, cfg *processorConfig, obj *T) error {
+	rv := reflect.ValueOf(obj).Elem()
+
+	now := cfg.now()
+
+	for _, group := range p.collectEncrypted(rv, p.storePlans.encryptFields) {
+		enc, ok := cfg.encryptors[group.algo]
+		if !ok {
+			// Removed after validation; the next operation re-validates
+			return newConfigError(ErrMissingEncryptor, string(group.algo), group.fieldNames())
+		}
+
+		if be, ok := enc.(BatchEncryptor); ok {
+			plaintexts := make([][]byte, len(group.values))
+			for i, v := range group.values {
+				plaintexts[i] = v.plaintext(now)
+			}
+			ciphertexts, err := be.EncryptBatch(p.batchContext(ctx, group.values), plaintexts)
+			for i, v := range group.values {
+				var ciphertext []byte
+				if i < len(ciphertexts) {
+					ciphertext = ciphertexts[i]
 				}
-				field.SetMapIndex(k, reflect.ValueOf(string(plaintext)))
+				v.wipe(plaintexts[i], ciphertext)
+			}
+			if err == nil && len(ciphertexts) != len(plaintexts) {
+				err = fmt.Errorf("batch returned %d results for %d values", len(ciphertexts), len(plaintexts))
+			}
+			if err != nil {
+				return newTransformError(ErrEncrypt, "encrypt", group.fieldNames(), err)
+			}
+			for i, v := range group.values {
+				v.setCiphertext(ciphertexts[i], cfg.ciphertextEncoding(v.plan))
 			}
 			continue
 		}
 
-		// Handle scalar string or []byte
-		if !field.CanSet() {
+		for _, v := range group.values {
+			plaintext := v.plaintext(now)
+			ciphertext, err := encryptContext(p.fieldContext(ctx, v.plan), enc, plaintext)
+			v.wipe(plaintext, ciphertext)
+			if err != nil {
+				return newTransformError(ErrEncrypt, "encrypt", v.name, err)
+			}
+			v.setCiphertext(ciphertext, cfg.ciphertextEncoding(v.plan))
+		}
+	}
+
+	return p.applyStreams(ctx, cfg, rv, p.storePlans.encryptFields, true)
+}
+
+// applyStreams wraps io.Reader fields in encrypting or decrypting readers.
+// Encryption is lazy: the payload is encrypted as the stored value is read.
+// Decryption reads the stream header (and unwraps envelope keys) up front.
+func (p *Processor[T]) applyStreams(ctx context.Context, cfg *processorConfig, rv reflect.Value, plans []processorFieldPlan, encrypt bool) error {
+	for i := range plans {
+		plan := &plans[i]
+		if !plan.isReader {
 			continue
 		}
 
-		var ciphertext []byte
-		var err error
+		field, ok := p.getField(rv, *plan)
+		if !ok || !field.CanSet() || field.IsNil() {
+			continue
+		}
+		src, _ := field.Interface().(io.Reader) // field type is io.Reader or io.ReadCloser
+		se, ok := cfg.encryptors[EncryptAlgo(plan.tagVal)].(StreamEncryptor)
+		if !ok {
+			// Removed or replaced after validation; the next operation re-validates
+			return newConfigError(ErrMissingEncryptor, plan.tagVal, plan.name)
+		}
+		fctx := p.fieldContext(ctx, plan)
 
-		if plan.isBytes {
-			ciphertext = field.Bytes()
+		var wrapped io.Reader
+		var err error
+		if encrypt {
+			wrapped, err = newEncryptingReader(fctx, se, src)
+			if err != nil {
+				return newTransformError(ErrEncrypt, "encrypt", plan.name, err)
+			}
 		} else {
-			ciphertext, err = base64.StdEncoding.DecodeString(field.String())
+			wrapped, err = se.DecryptReader(fctx, src)
 			if err != nil {
-				return newTransformError(ErrDecrypt, "decrypt", plan.name, err)
+				return newTransformError(decryptSentinel(err), "decrypt", plan.name, err)
 			}
 		}
 
-		plaintext, err := enc.Decrypt(ciphertext)
-		if err != nil {
-			return newTransformError(ErrDecrypt, "decrypt", plan.name, err)
+		// Keep the original Close so callers can release file handles.
+		if closer, ok := src.(io.Closer); ok && field.Type() == readCloserType {
+			wrapped = struct {
+				io.Reader
+				io.Closer
+			}{wrapped, closer}
 		}
+		field.Set(reflect.ValueOf(wrapped))
+	}
 
-		if plan.isBytes {
-			field.SetBytes(plaintext)
-		} else {
-			field.SetString(string(plaintext))
-		}
+	return nil
+}
+
+// decryptSentinel returns ErrKeyShredded for data whose key was deleted and
+// ErrExpired for data past its TTL, so callers can tell erased or expired
+// data from corrupt data, and ErrDecrypt otherwise.
+func decryptSentinel(err error) error {
+	switch {
+	case errors.Is(err, ErrKeyShredded):
+		return ErrKeyShredded
+	case errors.Is(err, ErrExpired):
+		return ErrExpired
+	default:
+		return ErrDecrypt
+	}
+}
+
+// hashSentinel returns ErrHashSaturated for hashes a HashLimiter turned
+// away, so callers can shed load instead of reporting a failure, and
+// ErrHash otherwise.
+func hashSentinel(err error) error {
+	if errors.Is(err, ErrHashSaturated) {
+		return ErrHashSaturated
+	}
+	return ErrHash
+}
+
+// fieldContext attaches the package-qualified type name and field path to
+// ctx, so field-aware encryptors such as Derived can select a per-field key.
+func (p *Processor[T]) fieldContext(ctx context.Context, plan *processorFieldPlan) context.Context {
+	return WithField(ctx, p.typePath, plan.name)
+}
+
+// qualifiedTypeName returns T's package path and name (e.g.,
+// "example.com/app.User"), so same-named types in different packages
+// stay distinct.
+func qualifiedTypeName[T any]() string {
+	t := reflect.TypeFor[T]()
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// batchContext attaches the field of each value in a batch to ctx.
+// A batch spans several fields, so it carries no single FieldInfo.
+func (p *Processor[T]) batchContext(ctx context.Context, values []encryptedValue) context.Context {
+	fields := make([]FieldInfo, len(values))
+	for i, v := range values {
+		fields[i] = FieldInfo{Type: p.typePath, Field: v.plan.name}
+	}
+	return withBatchFields(ctx, fields)
+}
+
+// subjectContext attaches the value of the cereal:"subject" field to ctx.
+func (p *Processor[T]) subjectContext(ctx context.Context, obj *T) context.Context {
+	if p.subjectPlan == nil {
+		return ctx
 	}
+	field, ok := p.getField(reflect.ValueOf(obj).Elem(), *p.subjectPlan)
+	if !ok || field.String() == "" {
+		return ctx
+	}
+	return WithSubject(ctx, field.String())
+}
 
+// encryptedValue is a single string or []byte targeted by an encrypt or
+// decrypt plan: a scalar field, a slice element, or a map value.
+type encryptedValue struct {
+	plan  *processorFieldPlan
+	name  string // field name, with index or key for collection elements
+	value []byte
+	text  bool // stored as a string, so ciphertext is text-encoded
+	set   func([]byte)
+}
+
+// plaintext returns the value to encrypt, framed when the field has frame options.
+func (v encryptedValue) plaintext(now time.Time) []byte {
+	if !v.plan.opts.framed() {
+		return v.value
+	}
+	return v.plan.opts.frame(v.value, now)
+}
+
+// setPlaintext stores decrypted data, removing the frame when the field has frame options.
+// Buffers copied into a string are zeroized.
+func (v encryptedValue) setPlaintext(data []byte, now time.Time) error {
+	payload := data
+	if v.plan.opts.framed() {
+		var err error
+		if payload, err = v.plan.opts.unframe(data, now); err != nil {
+			zeroize(data)
+			return err
+		}
+	}
+	v.set(payload)
+	if v.text {
+		zeroize(payload)
+		zeroize(data)
+	}
 	return nil
 }
 
-// applyEncrypt applies encrypt transformations via reflection.
-func (p *Processor[T]) applyEncrypt(obj *T) error {
-	rv := reflect.ValueOf(obj).Elem()
+// wipe zeroizes the plaintext copies made for encryption: the string copy
+// and the framed buffer. A []byte field's contents belong to the caller.
+// Buffers the ciphertext shares, as with an in-place encryptor, are kept.
+func (v encryptedValue) wipe(plaintext, ciphertext []byte) {
+	if v.plan.opts.framed() && !overlaps(plaintext, ciphertext) {
+		zeroize(plaintext)
+	}
+	if v.text && !overlaps(v.value, ciphertext) {
+		zeroize(v.value)
+	}
+}
 
-	for _, plan := range p.storePlans.encryptFields {
-		enc := p.encryptors[EncryptAlgo(plan.tagVal)]
+// setCiphertext stores ciphertext, text-encoding it for string targets
+// unless enc is EncodingBinary.
+func (v encryptedValue) setCiphertext(ciphertext []byte, enc CiphertextEncoding) {
+	if v.text && enc != EncodingBinary {
+		v.set([]byte(enc.encode(ciphertext)))
+		return
+	}
+	v.set(ciphertext)
+}
 
-		field, ok := p.getField(rv, plan)
+// encryptedGroup holds every value for one algorithm, in field order.
+type encryptedGroup struct {
+	algo   EncryptAlgo
+	values []encryptedValue
+}
+
+// fieldNames returns the distinct field names in the group, for errors
+// that cannot be attributed to a single value.
+func (g encryptedGroup) fieldNames() string {
+	names := make([]string, 0, len(g.values))
+	for i, v := range g.values {
+		if i > 0 && v.plan == g.values[i-1].plan {
+			continue
+		}
+		names = append(names, v.plan.name)
+	}
+	return strings.Join(names, ",")
+}
+
+// collectEncrypted gathers the values targeted by plans and groups them by
+// algorithm, in order of first appearance.
+func (p *Processor[T]) collectEncrypted(rv reflect.Value, plans []processorFieldPlan) []encryptedGroup {
+	var groups []encryptedGroup
+	index := make(map[EncryptAlgo]int)
+
+	add := func(v encryptedValue) {
+		algo := EncryptAlgo(v.plan.tagVal)
+		i, ok := index[algo]
+		if !ok {
+			i = len(groups)
+			index[algo] = i
+			groups = append(groups, encryptedGroup{algo: algo})
+		}
+		groups[i].values = append(groups[i].values, v)
+	}
+
+	for i := range plans {
+		plan := &plans[i]
+		if plan.isReader {
+			continue
+		}
+
+		field, ok := p.getField(rv, *plan)
 		if !ok {
 			continue
 		}
 
 		// Handle slice of strings
 		if plan.isSlice {
-			for i := 0; i < field.Len(); i++ {
-				elem := field.Index(i)
-				if elem.CanSet() {
-					ciphertext, err := enc.Encrypt([]byte(elem.String()))
-					if err != nil {
-						return newTransformError(ErrEncrypt, "encrypt", fmt.Sprintf("%s[%d]", plan.name, i), err)
-					}
-					elem.SetString(base64.StdEncoding.EncodeToString(ciphertext))
+			for j := 0; j < field.Len(); j++ {
+				elem := field.Index(j)
+				if !elem.CanSet() {
+					continue
 				}
+				add(encryptedValue{
+					plan:  plan,
+					name:  fmt.Sprintf("%s[%d]", plan.name, j),
+					value: []byte(elem.String()),
+					text:  true,
+					set:   func(b []byte) { elem.SetString(string(b)) },
+				})
 			}
 			continue
 		}
@@ -917,11 +1416,13 @@ func (p *Processor[T]) applyEncrypt(obj *T) error {
 			iter := field.MapRange()
 			for iter.Next() {
 				k, v := iter.Key(), iter.Value()
-				ciphertext, err := enc.Encrypt([]byte(v.String()))
-				if err != nil {
-					return newTransformError(ErrEncrypt, "encrypt", fmt.Sprintf("%s[%v]", plan.name, k.Interface()), err)
-				}
-				field.SetMapIndex(k, reflect.ValueOf(base64.StdEncoding.EncodeToString(ciphertext)))
+				add(encryptedValue{
+					plan:  plan,
+					name:  fmt.Sprintf("%s[%v]", plan.name, k.Interface()),
+					value: []byte(v.String()),
+					text:  true,
+					set:   func(b []byte) { field.SetMapIndex(k, reflect.ValueOf(string(b))) },
+				})
 			}
 			continue
 		}
@@ -931,34 +1432,37 @@ func (p *Processor[T]) applyEncrypt(obj *T) error {
 			continue
 		}
 
-		var plaintext []byte
-		if plan.isBytes {
-			plaintext = field.Bytes()
-		} else {
-			plaintext = []byte(field.String())
-		}
-
-		ciphertext, err := enc.Encrypt(plaintext)
-		if err != nil {
-			return newTransformError(ErrEncrypt, "encrypt", plan.name, err)
-		}
-
 		if plan.isBytes {
-			field.SetBytes(ciphertext)
+			add(encryptedValue{
+				plan:  plan,
+				name:  plan.name,
+				value: field.Bytes(),
+				set:   field.SetBytes,
+			})
 		} else {
-			field.SetString(base64.StdEncoding.EncodeToString(ciphertext))
+			add(encryptedValue{
+				plan:  plan,
+				name:  plan.name,
+				value: []byte(field.String()),
+				text:  true,
+				set:   func(b []byte) { field.SetString(string(b)) },
+			})
 		}
 	}
 
-	return nil
+	return groups
 }
 
 // applyMask applies mask transformations via reflection.
-func (p *Processor[T]) applyMask(obj *T) error {
+func (p *Processor[T]) applyMask(ctx context.Context, cfg *processorConfig, obj *T) error {
 	rv := reflect.ValueOf(obj).Elem()
 
 	for _, plan := range p.sendPlans.maskFields {
-		masker := p.maskers[MaskType(plan.tagVal)]
+		masker, ok := cfg.maskers[MaskType(plan.tagVal)]
+		if !ok {
+			// Removed after validation; the next operation re-validates
+			return newConfigError(ErrMissingMasker, plan.tagVal, plan.name)
+		}
 
 		field, ok := p.getField(rv, plan)
 		if !ok {
@@ -970,7 +1474,7 @@ func (p *Processor[T]) applyMask(obj *T) error {
 			for i := 0; i < field.Len(); i++ {
 				elem := field.Index(i)
 				if elem.CanSet() {
-					masked, err := masker.Mask(elem.String())
+					masked, err := maskContext(ctx, masker, elem.String())
 					if err != nil {
 						return newTransformError(ErrMask, "mask", fmt.Sprintf("%s[%d]", plan.name, i), err)
 					}
@@ -985,7 +1489,7 @@ func (p *Processor[T]) applyMask(obj *T) error {
 			iter := field.MapRange()
 			for iter.Next() {
 				k, v := iter.Key(), iter.Value()
-				masked, err := masker.Mask(v.String())
+				masked, err := maskContext(ctx, masker, v.String())
 				if err != nil {
 					return newTransformError(ErrMask, "mask", fmt.Sprintf("%s[%v]", plan.name, k.Interface()), err)
 				}
@@ -1006,7 +1510,7 @@ func (p *Processor[T]) applyMask(obj *T) error {
 			value = field.String()
 		}
 
-		masked, err := masker.Mask(value)
+		masked, err := maskContext(ctx, masker, value)
 		if err != nil {
 			return newTransformError(ErrMask, "mask", plan.name, err)
 		}
@@ -1,6 +1,7 @@
 package cereal
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -1262,23 +1263,90 @@ func (c *failingCodec) Unmarshal(data []byte, v any) error {
 
 // --- Validation error tests ---
 
-func TestProcessor_Validate_CalledOnce(t *testing.T) {
+func TestProcessor_Validate_RevalidatesAfterChange(t *testing.T) {
 	proc, _ := NewProcessor[EncryptUser]()
 
 	// First validate should fail (no encryptor)
-	err1 := proc.Validate()
-	if err1 == nil {
+	if err := proc.Validate(); err == nil {
 		t.Fatal("Validate() should fail without encryptor")
 	}
 
-	// Add encryptor
+	// Adding the encryptor clears the cached error
 	enc, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
 	proc.SetEncryptor(EncryptAES, enc)
+	if err := proc.Validate(); err != nil {
+		t.Errorf("Validate() after SetEncryptor error: %v", err)
+	}
+
+	// Setting nil removes it again
+	proc.SetEncryptor(EncryptAES, nil)
+	if err := proc.Validate(); !errors.Is(err, ErrMissingEncryptor) {
+		t.Errorf("Validate() after SetEncryptor(nil) = %v, want ErrMissingEncryptor", err)
+	}
+}
+
+func TestProcessor_RemoveHandlers(t *testing.T) {
+	ctx := context.Background()
+	enc, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+
+	t.Run("encryptor", func(t *testing.T) {
+		proc, _ := NewProcessor[EncryptUser]()
+		proc.SetEncryptor(EncryptAES, enc)
+		if _, err := proc.Store(ctx, EncryptUser{Email: testEmail}); err != nil {
+			t.Fatalf("Store() error: %v", err)
+		}
+
+		proc.RemoveEncryptor(EncryptAES)
+		if _, err := proc.Store(ctx, EncryptUser{Email: testEmail}); !errors.Is(err, ErrMissingEncryptor) {
+			t.Errorf("Store() after RemoveEncryptor = %v, want ErrMissingEncryptor", err)
+		}
+	})
+
+	t.Run("hasher", func(t *testing.T) {
+		proc, _ := NewProcessor[HashUser]()
+		if _, err := proc.Receive(ctx, HashUser{}); err != nil {
+			t.Fatalf("Receive() error: %v", err)
+		}
+
+		proc.RemoveHasher(HashSHA256)
+		if _, err := proc.Receive(ctx, HashUser{}); !errors.Is(err, ErrMissingHasher) {
+			t.Errorf("Receive() after RemoveHasher = %v, want ErrMissingHasher", err)
+		}
+	})
+
+	t.Run("masker", func(t *testing.T) {
+		proc, _ := NewProcessor[MaskUser]()
+		if _, err := proc.Send(ctx, MaskUser{Email: testEmail, SSN: "123-45-6789"}); err != nil {
+			t.Fatalf("Send() error: %v", err)
+		}
+
+		proc.RemoveMasker(MaskEmail)
+		if _, err := proc.Send(ctx, MaskUser{Email: testEmail, SSN: "123-45-6789"}); !errors.Is(err, ErrMissingMasker) {
+			t.Errorf("Send() after RemoveMasker = %v, want ErrMissingMasker", err)
+		}
+
+		proc.SetMasker(MaskEmail, builtinMaskers()[MaskEmail])
+		if _, err := proc.Send(ctx, MaskUser{Email: testEmail, SSN: "123-45-6789"}); err != nil {
+			t.Errorf("Send() after restoring masker error: %v", err)
+		}
+	})
+}
+
+func TestProcessor_HandlerRemovedAfterValidation(t *testing.T) {
+	// A handler removed between validation and the transform is reported,
+	// not dereferenced.
+	enc, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	proc, _ := NewProcessor[EncryptUser]()
+	proc.SetEncryptor(EncryptAES, enc)
+	if err := proc.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	cfg := proc.config.Load().clone() // a snapshot missing the encryptor, as if changed mid-operation
+	delete(cfg.encryptors, EncryptAES)
 
-	// Second validate still returns cached error (validateOnce)
-	err2 := proc.Validate()
-	if err2 == nil || err2.Error() != err1.Error() {
-		t.Error("Validate() should return cached error")
+	clone := EncryptUser{Email: testEmail}
+	if err := proc.applyEncrypt(context.Background(), cfg, &clone); !errors.Is(err, ErrMissingEncryptor) {
+		t.Errorf("applyEncrypt() = %v, want ErrMissingEncryptor", err)
 	}
 }
 
@@ -1703,8 +1771,8 @@ func TestProcessor_Store_NilMap(t *testing.T) {
 // MultiMissingUser requires multiple handlers.
 type MultiMissingUser struct {
 	ID       string `json:"id"`
-	Email    string `json:"email" store.encrypt:"aes"`
-	Password string `json:"password" store.encrypt:"rsa"`
+	Email    string `json:"email" store.encrypt:"aes" load.decrypt:"aes"`
+	Password string `json:"password" store.encrypt:"rsa" load.decrypt:"rsa"`
 }
 
 func (u MultiMissingUser) Clone() MultiMissingUser { return u }
@@ -2047,3 +2115,435 @@ func TestProcessor_T2T_NoCodecRequired(t *testing.T) {
 	}
 }
 
+
+// --- Context-aware capability tests ---
+
+type ctxKey struct{}
+
+// ctxEncryptor records the context value seen by EncryptContext/DecryptContext.
+type ctxEncryptor struct {
+	Encryptor
+	seen []any
+}
+
+func (e *ctxEncryptor) EncryptContext(ctx context.Context, plaintext []byte) ([]byte, error) {
+	e.seen = append(e.seen, ctx.Value(ctxKey{}))
+	return e.Encrypt(plaintext)
+}
+
+func (e *ctxEncryptor) DecryptContext(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	e.seen = append(e.seen, ctx.Value(ctxKey{}))
+	return e.Decrypt(ciphertext)
+}
+
+// ctxHasher fails when the context is cancelled.
+type ctxHasher struct {
+	Hasher
+}
+
+func (h *ctxHasher) HashContext(ctx context.Context, plaintext []byte) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return h.Hash(plaintext)
+}
+
+// ctxMasker masks with a value taken from the context.
+type ctxMasker struct{}
+
+func (m *ctxMasker) Mask(value string) (string, error) { return value, nil }
+
+func (m *ctxMasker) MaskContext(ctx context.Context, _ string) (string, error) {
+	v, _ := ctx.Value(ctxKey{}).(string)
+	return v, nil
+}
+
+func TestProcessor_EncryptorContext(t *testing.T) {
+	aes, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	enc := &ctxEncryptor{Encryptor: aes}
+
+	proc, _ := NewProcessor[EncryptUser]()
+	proc.SetEncryptor(EncryptAES, enc)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "tenant-a")
+	stored, err := proc.Store(ctx, EncryptUser{ID: "1", Email: testEmail})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	loaded, err := proc.Load(ctx, stored)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if loaded.Email != testEmail {
+		t.Errorf("Email = %q, want %q", loaded.Email, testEmail)
+	}
+
+	if len(enc.seen) != 2 || enc.seen[0] != "tenant-a" || enc.seen[1] != "tenant-a" {
+		t.Errorf("context values seen = %v, want [tenant-a tenant-a]", enc.seen)
+	}
+}
+
+func TestProcessor_HasherContext(t *testing.T) {
+	proc, _ := NewProcessor[HashUser]()
+	proc.SetHasher(HashSHA256, &ctxHasher{Hasher: SHA256Hasher()})
+
+	if _, err := proc.Receive(context.Background(), HashUser{Password: "secret"}); err != nil {
+		t.Fatalf("Receive() error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := proc.Receive(ctx, HashUser{Password: "secret"})
+	if !errors.Is(err, ErrHash) {
+		t.Errorf("expected ErrHash, got %v", err)
+	}
+	var te *TransformError
+	if !errors.As(err, &te) || !errors.Is(te.Cause, context.Canceled) {
+		t.Errorf("expected cause context.Canceled, got %v", err)
+	}
+}
+
+func TestProcessor_MaskerContext(t *testing.T) {
+	proc, _ := NewProcessor[MaskUser]()
+	proc.SetMasker(MaskEmail, &ctxMasker{})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "[masked]")
+	sent, err := proc.Send(ctx, MaskUser{Email: testEmail, SSN: "123-45-6789"})
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if sent.Email != "[masked]" {
+		t.Errorf("Email = %q, want %q", sent.Email, "[masked]")
+	}
+}
+
+// batchEncryptor counts batch calls and the values passed to each.
+type batchEncryptor struct {
+	Encryptor
+	encryptCalls []int
+	decryptCalls []int
+	short        bool
+}
+
+func (e *batchEncryptor) EncryptBatch(_ context.Context, plaintexts [][]byte) ([][]byte, error) {
+	e.encryptCalls = append(e.encryptCalls, len(plaintexts))
+	out := make([][]byte, len(plaintexts))
+	for i, pt := range plaintexts {
+		ct, err := e.Encrypt(pt)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = ct
+	}
+	if e.short {
+		out = out[:len(out)-1]
+	}
+	return out, nil
+}
+
+func (e *batchEncryptor) DecryptBatch(_ context.Context, ciphertexts [][]byte) ([][]byte, error) {
+	e.decryptCalls = append(e.decryptCalls, len(ciphertexts))
+	out := make([][]byte, len(ciphertexts))
+	for i, ct := range ciphertexts {
+		pt, err := e.Decrypt(ct)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = pt
+	}
+	return out, nil
+}
+
+// BatchUser mixes scalar, slice and map fields under one algorithm.
+type BatchUser struct {
+	Email  string            `json:"email" store.encrypt:"aes" load.decrypt:"aes"`
+	Tags   []string          `json:"tags" store.encrypt:"aes" load.decrypt:"aes"`
+	Labels map[string]string `json:"labels" store.encrypt:"aes" load.decrypt:"aes"`
+	Secret []byte            `json:"secret" store.encrypt:"aes" load.decrypt:"aes"`
+}
+
+func (u BatchUser) Clone() BatchUser {
+	c := u
+	c.Tags = append([]string(nil), u.Tags...)
+	c.Labels = make(map[string]string, len(u.Labels))
+	for k, v := range u.Labels {
+		c.Labels[k] = v
+	}
+	c.Secret = append([]byte(nil), u.Secret...)
+	return c
+}
+
+func TestProcessor_BatchEncryptor(t *testing.T) {
+	aes, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	enc := &batchEncryptor{Encryptor: aes}
+
+	proc, _ := NewProcessor[BatchUser]()
+	proc.SetEncryptor(EncryptAES, enc)
+
+	tags := make([]string, 200)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("tag-%d", i)
+	}
+	original := BatchUser{
+		Email:  testEmail,
+		Tags:   tags,
+		Labels: map[string]string{"a": "alpha", "b": "beta"},
+		Secret: []byte("raw secret"),
+	}
+
+	ctx := context.Background()
+	stored, err := proc.Store(ctx, original)
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	if len(enc.encryptCalls) != 1 || enc.encryptCalls[0] != 204 {
+		t.Errorf("EncryptBatch calls = %v, want [204]", enc.encryptCalls)
+	}
+	if stored.Tags[0] == original.Tags[0] || stored.Labels["a"] == "alpha" {
+		t.Error("collection values were not encrypted")
+	}
+
+	loaded, err := proc.Load(ctx, stored)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(enc.decryptCalls) != 1 || enc.decryptCalls[0] != 204 {
+		t.Errorf("DecryptBatch calls = %v, want [204]", enc.decryptCalls)
+	}
+	if loaded.Email != original.Email || loaded.Tags[199] != "tag-199" ||
+		loaded.Labels["b"] != "beta" || string(loaded.Secret) != "raw secret" {
+		t.Errorf("round trip mismatch: %+v", loaded)
+	}
+}
+
+func TestProcessor_BatchEncryptor_LengthMismatch(t *testing.T) {
+	aes, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+
+	proc, _ := NewProcessor[BatchUser]()
+	proc.SetEncryptor(EncryptAES, &batchEncryptor{Encryptor: aes, short: true})
+
+	_, err := proc.Store(context.Background(), BatchUser{Email: testEmail, Tags: []string{"x"}})
+	if !errors.Is(err, ErrEncrypt) {
+		t.Fatalf("expected ErrEncrypt, got %v", err)
+	}
+	var te *TransformError
+	if !errors.As(err, &te) || te.Field != "Email,Tags,Secret" {
+		t.Errorf("expected fields Email,Tags,Secret, got %v", err)
+	}
+}
+
+func TestProcessor_Close(t *testing.T) {
+	enc, _ := Derived(derivedMasterKey)
+	proc, _ := NewProcessor[DerivedUser]()
+	proc.SetEncryptor(EncryptDerived, enc)
+
+	ctx := context.Background()
+	stored, err := proc.Store(ctx, DerivedUser{Email: testEmail})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+
+	master := enc.(*derivedEncryptor).masterKey
+	if err := proc.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if err := proc.Close(); err != nil {
+		t.Errorf("second Close() error: %v", err)
+	}
+
+	if !bytes.Equal(master, make([]byte, len(master))) {
+		t.Error("Close should zeroize the derived master key")
+	}
+	if _, err := proc.Load(ctx, stored); !errors.Is(err, ErrClosed) {
+		t.Errorf("Load() after Close: expected ErrClosed, got %v", err)
+	}
+	if _, err := proc.Store(ctx, stored); !errors.Is(err, ErrClosed) {
+		t.Errorf("Store() after Close: expected ErrClosed, got %v", err)
+	}
+	if err := proc.Validate(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Validate() after Close: expected ErrClosed, got %v", err)
+	}
+}
+
+// SharedSecret has a Clone that shares the []byte with the original.
+type SharedSecret struct {
+	Secret []byte `json:"secret" store.encrypt:"aes,pad=pow2" load.decrypt:"aes"`
+	Note   string `json:"note" store.encrypt:"aes" load.decrypt:"aes"`
+}
+
+func (s SharedSecret) Clone() SharedSecret { return s }
+
+func TestProcessor_WipeKeepsCallerData(t *testing.T) {
+	enc, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	proc, _ := NewProcessor[SharedSecret]()
+	proc.SetEncryptor(EncryptAES, enc)
+
+	original := SharedSecret{Secret: []byte("raw secret"), Note: "note"}
+	stored, err := proc.Store(context.Background(), original)
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	if string(original.Secret) != "raw secret" || original.Note != "note" {
+		t.Errorf("Store() modified the caller's value: %+v", original)
+	}
+
+	loaded, err := proc.Load(context.Background(), stored)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if string(loaded.Secret) != "raw secret" || loaded.Note != "note" {
+		t.Errorf("round trip mismatch: %+v", loaded)
+	}
+}
+
+// identityEncryptor returns its input, as an in-place transform might.
+type identityEncryptor struct{}
+
+func (identityEncryptor) Encrypt(plaintext []byte) ([]byte, error)  { return plaintext, nil }
+func (identityEncryptor) Decrypt(ciphertext []byte) ([]byte, error) { return ciphertext, nil }
+
+func TestProcessor_WipeKeepsSharedCiphertext(t *testing.T) {
+	for name, enc := range map[string]Encryptor{
+		"identity": identityEncryptor{},
+		"padded":   Padded(identityEncryptor{}, PadPowerOfTwo()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			proc, _ := NewProcessor[SharedSecret]()
+			proc.SetEncryptor(EncryptAES, enc)
+
+			stored, err := proc.Store(context.Background(), SharedSecret{Secret: []byte("raw secret"), Note: "note"})
+			if err != nil {
+				t.Fatalf("Store() error: %v", err)
+			}
+			loaded, err := proc.Load(context.Background(), stored)
+			if err != nil {
+				t.Fatalf("Load() error: %v", err)
+			}
+			if string(loaded.Secret) != "raw secret" || loaded.Note != "note" {
+				t.Errorf("round trip mismatch: %+v", loaded)
+			}
+		})
+	}
+}
+
+// --- Encrypt/decrypt pairing tests ---
+
+type UnpairedStoreUser struct {
+	Email string `json:"email" store.encrypt:"aes"`
+}
+
+func (u UnpairedStoreUser) Clone() UnpairedStoreUser { return u }
+
+type UnpairedLoadUser struct {
+	Email string `json:"email" load.decrypt:"aes"`
+}
+
+func (u UnpairedLoadUser) Clone() UnpairedLoadUser { return u }
+
+type MismatchedPairUser struct {
+	Email string `json:"email" store.encrypt:"aes" load.decrypt:"xchacha"`
+}
+
+func (u MismatchedPairUser) Clone() MismatchedPairUser { return u }
+
+type MismatchedFrameUser struct {
+	Email string `json:"email" store.encrypt:"aes" load.decrypt:"aes,ttl=1h"`
+}
+
+func (u MismatchedFrameUser) Clone() MismatchedFrameUser { return u }
+
+type DifferentFrameUser struct {
+	Email string `json:"email" store.encrypt:"aes,compress" load.decrypt:"aes,pad=pow2"`
+}
+
+func (u DifferentFrameUser) Clone() DifferentFrameUser { return u }
+
+type NestedUnpairedUser struct {
+	Inner struct {
+		Email string `json:"email" store.encrypt:"aes"`
+	} `json:"inner"`
+}
+
+func (u NestedUnpairedUser) Clone() NestedUnpairedUser { return u }
+
+type OneWayUser struct {
+	Audit  string `json:"audit" store.encrypt:"aes,writeonly"`
+	Legacy string `json:"legacy" load.decrypt:"aes,readonly"`
+}
+
+func (u OneWayUser) Clone() OneWayUser { return u }
+
+type PairedWriteOnlyUser struct {
+	Email string `json:"email" store.encrypt:"aes,writeonly" load.decrypt:"aes"`
+}
+
+func (u PairedWriteOnlyUser) Clone() PairedWriteOnlyUser { return u }
+
+type MisplacedReadOnlyUser struct {
+	Email string `json:"email" store.encrypt:"aes,readonly"`
+}
+
+func (u MisplacedReadOnlyUser) Clone() MisplacedReadOnlyUser { return u }
+
+func TestNewProcessor_UnpairedTags(t *testing.T) {
+	check := func(t *testing.T, err error, field string) {
+		t.Helper()
+		if !errors.Is(err, ErrUnpairedTag) {
+			t.Fatalf("NewProcessor() error = %v, want ErrUnpairedTag", err)
+		}
+		var ce *ConfigError
+		if !errors.As(err, &ce) || ce.Field != field {
+			t.Errorf("ConfigError = %+v, want field %s", ce, field)
+		}
+	}
+
+	t.Run("store without load", func(t *testing.T) {
+		_, err := NewProcessor[UnpairedStoreUser]()
+		check(t, err, "Email")
+	})
+	t.Run("load without store", func(t *testing.T) {
+		_, err := NewProcessor[UnpairedLoadUser]()
+		check(t, err, "Email")
+	})
+	t.Run("mismatched algorithms", func(t *testing.T) {
+		_, err := NewProcessor[MismatchedPairUser]()
+		check(t, err, "Email")
+		if !strings.Contains(err.Error(), "xchacha") {
+			t.Errorf("error %q does not name the load.decrypt algorithm", err)
+		}
+	})
+	t.Run("frame only on load", func(t *testing.T) {
+		_, err := NewProcessor[MismatchedFrameUser]()
+		check(t, err, "Email")
+	})
+	t.Run("different frames", func(t *testing.T) {
+		_, err := NewProcessor[DifferentFrameUser]()
+		check(t, err, "Email")
+	})
+	t.Run("nested", func(t *testing.T) {
+		_, err := NewProcessor[NestedUnpairedUser]()
+		check(t, err, "Inner.Email")
+	})
+}
+
+func TestNewProcessor_OneWayTags(t *testing.T) {
+	if _, err := NewProcessor[OneWayUser](); err != nil {
+		t.Errorf("NewProcessor() with writeonly/readonly error: %v", err)
+	}
+
+	// Opt-outs on a paired field, or on the wrong tag, are invalid.
+	if _, err := NewProcessor[PairedWriteOnlyUser](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("writeonly on paired field error = %v, want ErrInvalidTag", err)
+	}
+	if _, err := NewProcessor[MisplacedReadOnlyUser](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("readonly on store.encrypt error = %v, want ErrInvalidTag", err)
+	}
+}
+
+func TestNewProcessor_OverrideSkipsPairing(t *testing.T) {
+	// EncryptableUser has store.encrypt without load.decrypt, handled by its override.
+	if _, err := NewProcessor[EncryptableUser](); err != nil {
+		t.Errorf("NewProcessor() error: %v", err)
+	}
+}
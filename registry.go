@@ -12,6 +12,7 @@ type typeFieldPlans struct {
 	load     loadPlan
 	store    storePlan
 	send     sendPlan
+	subject  *processorFieldPlan // field tagged cereal:"subject", if any
 	typeName string
 }
 
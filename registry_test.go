@@ -50,7 +50,7 @@ func TestNewProcessor_SeparateEncryptorState(t *testing.T) {
 	cereal.ResetPlansCache()
 
 	key1 := []byte("32-byte-key-for-aes-256-encrypt!")
-	key2 := []byte("different-key-for-aes-256-enc!!")
+	key2 := []byte("different-key-for-aes-256-enc!!!")
 
 	enc1, _ := cereal.AES(key1)
 	enc2, _ := cereal.AES(key2)
@@ -0,0 +1,157 @@
+package cereal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// selfTestProbe is the plaintext round-tripped through each encryptor.
+var selfTestProbe = []byte("cereal self-test probe")
+
+// errRoundTripMismatch indicates a self-test decrypted to different bytes.
+var errRoundTripMismatch = errors.New("round trip returned different plaintext")
+
+// roundTrip is the outcome of a self-test round trip for one field.
+// A nil decryptErr with a non-nil encryptErr means decryption was not tested.
+type roundTrip struct {
+	encryptErr    error
+	decryptErr    error
+	cannotEncrypt bool // declared through KeyCapabilities, so not attempted
+}
+
+// selfTestKey identifies a round trip: field-keyed encryptors such as
+// Derived use a different key for each field.
+type selfTestKey struct {
+	algo  EncryptAlgo
+	field string
+}
+
+// SelfTest round-trips a probe value through every encryptor the type's
+// fields use, in each field's context, returning a ConfigError for each
+// field whose boundary the encryptor cannot serve: ErrEncryptUnsupported
+// for store.encrypt fields whose encryptor declares it cannot encrypt, and
+// ErrDecryptUnsupported for load.decrypt fields whose probe fails to
+// decrypt or decrypts to other bytes. All errors are joined into one.
+//
+// Other encryption failures, such as an unreachable KMS, say nothing about
+// the key and are reported with ErrEncrypt. Fields that could not be tested
+// are reported with ErrSelfTestSkipped: those whose encryptor needs a
+// tenant or subject that ctx lacks (Tenant, Subject), and load.decrypt
+// fields whose encryptor cannot encrypt a probe, such as RSA with only a
+// private key. Fields handled by override interfaces are not tested.
+func (p *Processor[T]) SelfTest(ctx context.Context) error {
+	p.inflight.RLock()
+	defer p.inflight.RUnlock()
+
+	cfg := p.config.Load()
+	if cfg.closed {
+		return ErrClosed
+	}
+	return p.runSelfTest(ctx, cfg, false)
+}
+
+// runSelfTest implements SelfTest for cfg. With background set, as during
+// validation, untestable fields are skipped silently rather than reported.
+// Caller must hold p.inflight.
+func (p *Processor[T]) runSelfTest(ctx context.Context, cfg *processorConfig, background bool) error {
+	var zero T
+	_, hasDecryptable := any(&zero).(Decryptable)
+	_, hasEncryptable := any(&zero).(Encryptable)
+
+	results := make(map[selfTestKey]roundTrip)
+	probe := func(plan *processorFieldPlan) (selfTestKey, roundTrip) {
+		key := selfTestKey{algo: EncryptAlgo(plan.tagVal), field: plan.name}
+		if rt, ok := results[key]; ok {
+			return key, rt
+		}
+		rt := p.roundTrip(p.fieldContext(ctx, plan), cfg, key.algo)
+		results[key] = rt
+		return key, rt
+	}
+
+	var errs []error
+	skip := func(plan *processorFieldPlan, cause error) {
+		if !background {
+			errs = append(errs, newConfigError(
+				fmt.Errorf("%w: %w", ErrSelfTestSkipped, cause), plan.tagVal, plan.name))
+		}
+	}
+	// A failed encryption is reported once, though both boundaries use it.
+	failed := make(map[selfTestKey]bool)
+	fail := func(key selfTestKey, plan *processorFieldPlan, cause error) {
+		if !failed[key] {
+			failed[key] = true
+			errs = append(errs, newConfigError(
+				fmt.Errorf("%w: self-test: %w", ErrEncrypt, cause), plan.tagVal, plan.name))
+		}
+	}
+
+	if !hasEncryptable {
+		for i := range p.storePlans.encryptFields {
+			plan := &p.storePlans.encryptFields[i]
+			key, rt := probe(plan)
+			switch {
+			case rt.encryptErr == nil:
+			case rt.cannotEncrypt:
+				errs = append(errs, newConfigError(
+					fmt.Errorf("%w: self-test: %w", ErrEncryptUnsupported, rt.encryptErr),
+					plan.tagVal, plan.name))
+			case needsOperationContext(rt.encryptErr):
+				skip(plan, rt.encryptErr)
+			default:
+				fail(key, plan, rt.encryptErr)
+			}
+		}
+	}
+	if !hasDecryptable {
+		for i := range p.loadPlans.decryptFields {
+			plan := &p.loadPlans.decryptFields[i]
+			key, rt := probe(plan)
+			switch {
+			case rt.decryptErr != nil:
+				errs = append(errs, newConfigError(
+					fmt.Errorf("%w: self-test: %w", ErrDecryptUnsupported, rt.decryptErr),
+					plan.tagVal, plan.name))
+			case rt.encryptErr == nil:
+			case rt.cannotEncrypt, needsOperationContext(rt.encryptErr):
+				skip(plan, fmt.Errorf("decryption untested, no probe ciphertext: %w", rt.encryptErr))
+			default:
+				fail(key, plan, rt.encryptErr)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// needsOperationContext reports whether err means the encryptor needs a
+// tenant or subject in the context, rather than that its key is wrong.
+func needsOperationContext(err error) bool {
+	return errors.Is(err, ErrUnknownTenant) || errors.Is(err, ErrMissingSubject)
+}
+
+// roundTrip encrypts and decrypts the probe with the encryptor for algo.
+func (p *Processor[T]) roundTrip(ctx context.Context, cfg *processorConfig, algo EncryptAlgo) roundTrip {
+	enc, ok := cfg.encryptors[algo]
+	if !ok {
+		return roundTrip{encryptErr: ErrMissingEncryptor, decryptErr: ErrMissingEncryptor, cannotEncrypt: true}
+	}
+	if kc, ok := enc.(KeyCapabilities); ok && !kc.CanEncrypt() {
+		return roundTrip{encryptErr: ErrEncryptUnsupported, cannotEncrypt: true}
+	}
+
+	ciphertext, err := encryptContext(ctx, enc, selfTestProbe)
+	if err != nil {
+		return roundTrip{encryptErr: err}
+	}
+
+	plaintext, err := decryptContext(ctx, enc, ciphertext)
+	if err != nil {
+		return roundTrip{decryptErr: err}
+	}
+	if !bytes.Equal(plaintext, selfTestProbe) {
+		return roundTrip{decryptErr: errRoundTripMismatch}
+	}
+	return roundTrip{}
+}
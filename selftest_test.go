@@ -0,0 +1,248 @@
+package cereal
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+// mismatchedEncryptor encrypts and decrypts with different keys, as a
+// misconfigured custom encryptor might. It does not implement KeyCapabilities.
+type mismatchedEncryptor struct {
+	enc, dec Encryptor
+}
+
+func (m mismatchedEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	return m.enc.Encrypt(plaintext)
+}
+
+func (m mismatchedEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	return m.dec.Decrypt(ciphertext)
+}
+
+// failingEncryptor fails every operation.
+type failingEncryptor struct{}
+
+func (failingEncryptor) Encrypt([]byte) ([]byte, error) { return nil, errors.New("hsm offline") }
+func (failingEncryptor) Decrypt([]byte) ([]byte, error) { return nil, errors.New("hsm offline") }
+
+// StoreOnlyUser encrypts on store but never decrypts.
+type StoreOnlyUser struct {
+	Email string `json:"email" store.encrypt:"aes,writeonly"`
+}
+
+func (u StoreOnlyUser) Clone() StoreOnlyUser { return u }
+
+// DecryptOnlyUser reads legacy ciphertext but never writes it.
+type DecryptOnlyUser struct {
+	Legacy string `json:"legacy" load.decrypt:"rsa,readonly"`
+}
+
+func (u DecryptOnlyUser) Clone() DecryptOnlyUser { return u }
+
+func TestProcessor_SelfTest(t *testing.T) {
+	ctx := context.Background()
+	good, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	other, _ := AES([]byte("another-32-byte-key-for-aes-256!"))
+
+	t.Run("passes", func(t *testing.T) {
+		proc, _ := NewProcessor[EncryptUser]()
+		proc.SetEncryptor(EncryptAES, good)
+		if err := proc.SelfTest(ctx); err != nil {
+			t.Errorf("SelfTest() error: %v", err)
+		}
+	})
+
+	t.Run("decrypt gap", func(t *testing.T) {
+		proc, _ := NewProcessor[EncryptUser]()
+		proc.SetEncryptor(EncryptAES, mismatchedEncryptor{enc: good, dec: other})
+
+		err := proc.SelfTest(ctx)
+		if !errors.Is(err, ErrDecryptUnsupported) {
+			t.Fatalf("SelfTest() = %v, want ErrDecryptUnsupported", err)
+		}
+		if errors.Is(err, ErrEncryptUnsupported) {
+			t.Errorf("SelfTest() reported an encrypt gap: %v", err)
+		}
+		var ce *ConfigError
+		if !errors.As(err, &ce) || ce.Field != "Email" || ce.Algorithm != "aes" {
+			t.Errorf("ConfigError = %+v", ce)
+		}
+	})
+
+	t.Run("encrypt failure is not a key gap", func(t *testing.T) {
+		proc, _ := NewProcessor[EncryptUser]()
+		proc.SetEncryptor(EncryptAES, failingEncryptor{})
+
+		err := proc.SelfTest(ctx)
+		if !errors.Is(err, ErrEncrypt) {
+			t.Errorf("SelfTest() = %v, want ErrEncrypt", err)
+		}
+		// Only declared capabilities and probe mismatches are key gaps.
+		if errors.Is(err, ErrEncryptUnsupported) || errors.Is(err, ErrDecryptUnsupported) {
+			t.Errorf("SelfTest() reported a key gap: %v", err)
+		}
+	})
+
+	t.Run("unused boundary is not tested", func(t *testing.T) {
+		proc, _ := NewProcessor[StoreOnlyUser]()
+		proc.SetEncryptor(EncryptAES, mismatchedEncryptor{enc: good, dec: other})
+		if err := proc.SelfTest(ctx); err != nil {
+			t.Errorf("SelfTest() error: %v", err)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		proc, _ := NewProcessor[EncryptUser]()
+		_ = proc.Close()
+		if err := proc.SelfTest(ctx); !errors.Is(err, ErrClosed) {
+			t.Errorf("SelfTest() = %v, want ErrClosed", err)
+		}
+	})
+}
+
+func TestProcessor_SetSelfTest(t *testing.T) {
+	good, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	other, _ := AES([]byte("another-32-byte-key-for-aes-256!"))
+	bad := mismatchedEncryptor{enc: good, dec: other}
+
+	// Without the self-test, the misconfiguration passes validation.
+	proc, _ := NewProcessor[EncryptUser]()
+	proc.SetEncryptor(EncryptAES, bad)
+	if err := proc.Validate(); err != nil {
+		t.Fatalf("Validate() without self-test error: %v", err)
+	}
+
+	proc, _ = NewProcessor[EncryptUser]()
+	proc.SetEncryptor(EncryptAES, bad).SetSelfTest(true)
+	if err := proc.Validate(); !errors.Is(err, ErrDecryptUnsupported) {
+		t.Errorf("Validate() = %v, want ErrDecryptUnsupported", err)
+	}
+	if _, err := proc.Store(context.Background(), EncryptUser{Email: testEmail}); !errors.Is(err, ErrDecryptUnsupported) {
+		t.Errorf("Store() = %v, want validation error", err)
+	}
+}
+
+func TestProcessor_SelfTestFieldContext(t *testing.T) {
+	// Derived needs the field context that Store and Load supply.
+	enc, _ := Derived(derivedMasterKey)
+	proc, _ := NewProcessor[DerivedUser]()
+	proc.SetEncryptor(EncryptDerived, enc).SetSelfTest(true)
+
+	if err := proc.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if _, err := proc.Store(context.Background(), DerivedUser{Email: testEmail}); err != nil {
+		t.Errorf("Store() error: %v", err)
+	}
+}
+
+func TestProcessor_SelfTestNeedsContext(t *testing.T) {
+	keys := newTenantKeys()
+
+	t.Run("tenant", func(t *testing.T) {
+		proc, _ := NewProcessor[EncryptUser]()
+		proc.SetEncryptor(EncryptAES, Tenant(tenantFromContext, keys.resolve)).SetSelfTest(true)
+
+		// Validation has no tenant to offer, so it skips the field.
+		if err := proc.Validate(); err != nil {
+			t.Fatalf("Validate() error: %v", err)
+		}
+
+		err := proc.SelfTest(context.Background())
+		if !errors.Is(err, ErrSelfTestSkipped) || !errors.Is(err, ErrUnknownTenant) {
+			t.Errorf("SelfTest() without tenant = %v, want ErrSelfTestSkipped", err)
+		}
+		if errors.Is(err, ErrEncryptUnsupported) || errors.Is(err, ErrDecryptUnsupported) {
+			t.Errorf("SelfTest() reported a missing tenant as a key gap: %v", err)
+		}
+
+		if err := proc.SelfTest(withTenant("acme")); err != nil {
+			t.Errorf("SelfTest() with tenant error: %v", err)
+		}
+	})
+
+	t.Run("subject", func(t *testing.T) {
+		proc := newSubjectProcessor(t, MemorySubjectKeyStore())
+		proc.SetSelfTest(true)
+
+		if err := proc.Validate(); err != nil {
+			t.Fatalf("Validate() error: %v", err)
+		}
+
+		err := proc.SelfTest(context.Background())
+		if !errors.Is(err, ErrSelfTestSkipped) || !errors.Is(err, ErrMissingSubject) {
+			t.Errorf("SelfTest() without subject = %v, want ErrSelfTestSkipped", err)
+		}
+		if err := proc.SelfTest(WithSubject(context.Background(), "self-test")); err != nil {
+			t.Errorf("SelfTest() with subject error: %v", err)
+		}
+	})
+}
+
+func TestProcessor_SelfTestDecryptOnly(t *testing.T) {
+	priv, _ := rsa.GenerateKey(rand.Reader, 2048)
+	proc, _ := NewProcessor[DecryptOnlyUser]()
+	proc.SetEncryptor(EncryptRSA, RSA(nil, priv))
+
+	// Without a public key there is no probe ciphertext to decrypt.
+	err := proc.SelfTest(context.Background())
+	if !errors.Is(err, ErrSelfTestSkipped) {
+		t.Fatalf("SelfTest() = %v, want ErrSelfTestSkipped", err)
+	}
+	if errors.Is(err, ErrDecryptUnsupported) {
+		t.Errorf("SelfTest() reported an untested key as failing: %v", err)
+	}
+	var ce *ConfigError
+	if !errors.As(err, &ce) || ce.Field != "Legacy" || ce.Algorithm != "rsa" {
+		t.Errorf("ConfigError = %+v", ce)
+	}
+
+	// A read-only service is valid: validation skips the untestable field.
+	proc.SetSelfTest(true)
+	if err := proc.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	ciphertext, _ := RSA(&priv.PublicKey, nil).Encrypt([]byte(testEmail))
+	loaded, err := proc.Load(context.Background(), DecryptOnlyUser{Legacy: base64.StdEncoding.EncodeToString(ciphertext)})
+	if err != nil || loaded.Legacy != testEmail {
+		t.Errorf("Load() = %q, %v", loaded.Legacy, err)
+	}
+}
+
+// flakyEncryptor fails to encrypt until recovered, as an unreachable KMS would.
+type flakyEncryptor struct {
+	Encryptor
+	down atomic.Bool
+}
+
+func (f *flakyEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	if f.down.Load() {
+		return nil, errors.New("kms unreachable")
+	}
+	return f.Encryptor.Encrypt(plaintext)
+}
+
+func TestProcessor_SelfTestRetriesOperationalFailure(t *testing.T) {
+	aes, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+	flaky := &flakyEncryptor{Encryptor: aes}
+	flaky.down.Store(true)
+
+	proc, _ := NewProcessor[EncryptUser]()
+	proc.SetEncryptor(EncryptAES, flaky).SetSelfTest(true)
+
+	ctx := context.Background()
+	if _, err := proc.Store(ctx, EncryptUser{Email: testEmail}); !errors.Is(err, ErrEncrypt) {
+		t.Fatalf("Store() while down = %v, want ErrEncrypt", err)
+	}
+
+	// The failure was not cached, so recovery needs no configuration change.
+	flaky.down.Store(false)
+	if _, err := proc.Store(ctx, EncryptUser{Email: testEmail}); err != nil {
+		t.Errorf("Store() after recovery error: %v", err)
+	}
+}
@@ -18,6 +18,8 @@ var (
 	SignalStoreComplete    = capitan.NewSignal("codec.store.complete", "Store operation finished")
 	SignalSendStart        = capitan.NewSignal("codec.send.start", "Send operation beginning")
 	SignalSendComplete     = capitan.NewSignal("codec.send.complete", "Send operation finished")
+	SignalKeyRotated       = capitan.NewSignal("codec.key.rotated", "Encryption keys reloaded from a key source")
+	SignalHashAdmission    = capitan.NewSignal("codec.hash.admission", "Password hash admitted or rejected by a hash limiter")
 )
 
 // Keys for typed event data.
@@ -32,6 +34,9 @@ var (
 	KeyHashedCount    = capitan.NewIntKey("hashed_count")
 	KeyMaskedCount    = capitan.NewIntKey("masked_count")
 	KeyRedactedCount  = capitan.NewIntKey("redacted_count")
+	KeyKeySource      = capitan.NewStringKey("key_source")
+	KeyKeyID          = capitan.NewStringKey("key_id")
+	KeyHashWeight     = capitan.NewInt64Key("hash_weight")
 )
 
 // emitProcessorCreated emits an event when a processor is created.
@@ -140,3 +145,32 @@ func emitSendComplete(ctx context.Context, contentType, typeName string, size in
 		capitan.Emit(ctx, SignalSendComplete, fields...)
 	}
 }
+
+// emitKeyRotated emits an event when a key reload installs new keys, or fails.
+func emitKeyRotated(ctx context.Context, source, keyID string, err error) {
+	fields := []capitan.Field{
+		KeyKeySource.Field(source),
+		KeyKeyID.Field(keyID),
+	}
+	if err != nil {
+		fields = append(fields, KeyError.Field(err))
+		capitan.Error(ctx, SignalKeyRotated, fields...)
+	} else {
+		capitan.Emit(ctx, SignalKeyRotated, fields...)
+	}
+}
+
+// emitHashAdmission emits an event when a hash limiter admits a hash, with
+// the time it spent queued, or rejects it.
+func emitHashAdmission(ctx context.Context, weight int64, queued time.Duration, err error) {
+	fields := []capitan.Field{
+		KeyHashWeight.Field(weight),
+		KeyDuration.Field(queued),
+	}
+	if err != nil {
+		fields = append(fields, KeyError.Field(err))
+		capitan.Error(ctx, SignalHashAdmission, fields...)
+	} else {
+		capitan.Emit(ctx, SignalHashAdmission, fields...)
+	}
+}
@@ -60,6 +60,22 @@ func TestEmitSendComplete_Error(_ *testing.T) {
 	emitSendComplete(context.Background(), "application/json", "TestType", 0, 100*time.Millisecond, 0, 0, errors.New("test error"))
 }
 
+func TestEmitKeyRotated_Success(_ *testing.T) {
+	emitKeyRotated(context.Background(), "aes", "v2", nil)
+}
+
+func TestEmitKeyRotated_Error(_ *testing.T) {
+	emitKeyRotated(context.Background(), "aes", "", errors.New("test error"))
+}
+
+func TestEmitHashAdmission_Success(_ *testing.T) {
+	emitHashAdmission(context.Background(), 64<<20, 10*time.Millisecond, nil)
+}
+
+func TestEmitHashAdmission_Error(_ *testing.T) {
+	emitHashAdmission(context.Background(), 64<<20, time.Second, errors.New("test error"))
+}
+
 func TestSignalVariables(t *testing.T) {
 	// Verify signals are properly initialized
 	signals := []struct {
@@ -75,6 +91,8 @@ func TestSignalVariables(t *testing.T) {
 		{"SignalStoreComplete", SignalStoreComplete},
 		{"SignalSendStart", SignalSendStart},
 		{"SignalSendComplete", SignalSendComplete},
+		{"SignalKeyRotated", SignalKeyRotated},
+		{"SignalHashAdmission", SignalHashAdmission},
 	}
 
 	for _, s := range signals {
@@ -100,6 +118,9 @@ func TestKeyVariables(t *testing.T) {
 		{"KeyHashedCount", KeyHashedCount},
 		{"KeyMaskedCount", KeyMaskedCount},
 		{"KeyRedactedCount", KeyRedactedCount},
+		{"KeyKeySource", KeyKeySource},
+		{"KeyKeyID", KeyKeyID},
+		{"KeyHashWeight", KeyHashWeight},
 	}
 
 	for _, k := range keys {
@@ -0,0 +1,366 @@
+package cereal
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Stream layout (STREAM construction, Hoang et al. 2015):
+//
+//	[1 byte version][nonce prefix][segment 0][segment 1]...[final segment]
+//
+// Each segment seals up to streamSegmentSize bytes of plaintext. Its nonce is
+// the random prefix, a 4-byte big-endian segment counter, and a 1-byte flag
+// set only on the final segment, so reordering, dropping, or truncating
+// segments fails authentication.
+//
+// The prefix is the AEAD nonce size minus the suffix: 19 bytes for XChaCha,
+// but only 7 for AES-GCM, which collide after a few thousand streams. AES-GCM
+// therefore streams only under a fresh key per stream, as Envelope does.
+const (
+	streamVersion     byte = 1
+	streamSegmentSize      = 64 * 1024
+	streamNonceSuffix      = 5 // counter + final flag
+)
+
+// errStreamClosed indicates a write after Close.
+var errStreamClosed = errors.New("stream closed")
+
+// StreamEncryptor is implemented by encryptors that can encrypt payloads
+// incrementally without holding them in memory. XChaCha and Envelope
+// encryptors implement it. AES does not: a long-lived AES-GCM key cannot give
+// each stream a nonce prefix long enough to rule out reuse, so stream under
+// Envelope, which uses a fresh data key per stream, instead.
+type StreamEncryptor interface {
+	// EncryptWriter returns a writer that encrypts everything written to it
+	// into w. Close must be called to write the final segment; it does not
+	// close w.
+	EncryptWriter(ctx context.Context, w io.Writer) (io.WriteCloser, error)
+
+	// DecryptReader returns a reader that decrypts the stream read from r.
+	// It returns an error wrapping ErrDecrypt if the stream was modified or
+	// truncated; plaintext read before the error must not be trusted.
+	DecryptReader(ctx context.Context, r io.Reader) (io.Reader, error)
+}
+
+// NewEncryptWriter returns a writer that encrypts into w with enc.
+// enc must implement StreamEncryptor.
+func NewEncryptWriter(ctx context.Context, enc Encryptor, w io.Writer) (io.WriteCloser, error) {
+	se, ok := enc.(StreamEncryptor)
+	if !ok {
+		return nil, fmt.Errorf("%w: encryptor does not support streaming", ErrEncryptUnsupported)
+	}
+	return se.EncryptWriter(ctx, w)
+}
+
+// NewDecryptReader returns a reader that decrypts the stream read from r with enc.
+// enc must implement StreamEncryptor.
+func NewDecryptReader(ctx context.Context, enc Encryptor, r io.Reader) (io.Reader, error) {
+	se, ok := enc.(StreamEncryptor)
+	if !ok {
+		return nil, fmt.Errorf("%w: encryptor does not support streaming", ErrDecryptUnsupported)
+	}
+	return se.DecryptReader(ctx, r)
+}
+
+// streamWriter encrypts written data in fixed-size segments.
+type streamWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	nonce   []byte // prefix, counter, final flag
+	counter uint32
+	buf     []byte // pending plaintext, at most one segment
+	out     []byte // sealed segment scratch space
+	closed  bool
+	err     error
+}
+
+// newStreamWriter writes the stream header to w and returns the writer.
+func newStreamWriter(w io.Writer, aead cipher.AEAD) (*streamWriter, error) {
+	header := make([]byte, 1+aead.NonceSize()-streamNonceSuffix)
+	header[0] = streamVersion
+	if _, err := io.ReadFull(rand.Reader, header[1:]); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce, header[1:])
+
+	return &streamWriter{
+		w:     w,
+		aead:  aead,
+		nonce: nonce,
+		buf:   make([]byte, 0, streamSegmentSize),
+	}, nil
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errStreamClosed
+	}
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	n := 0
+	for len(p) > 0 {
+		// Flush only when more data arrives, so the final segment is never empty
+		// unless the whole stream is.
+		if len(s.buf) == streamSegmentSize {
+			if err := s.flush(false); err != nil {
+				return n, err
+			}
+		}
+		c := copy(s.buf[len(s.buf):cap(s.buf)], p)
+		s.buf = s.buf[:len(s.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// Close writes the final segment. It does not close the underlying writer.
+func (s *streamWriter) Close() error {
+	if s.closed {
+		return s.err
+	}
+	s.closed = true
+	if s.err == nil {
+		s.err = s.flush(true)
+	}
+	zeroize(s.buf[:cap(s.buf)])
+	return s.err
+}
+
+// flush seals and writes the pending segment.
+func (s *streamWriter) flush(final bool) error {
+	if s.counter == math.MaxUint32 {
+		s.err = errors.New("stream too long")
+		return s.err
+	}
+	setStreamNonce(s.nonce, s.counter, final)
+	s.out = s.aead.Seal(s.out[:0], s.nonce, s.buf, nil)
+	if _, err := s.w.Write(s.out); err != nil {
+		s.err = err
+		return err
+	}
+	s.counter++
+	s.buf = s.buf[:0]
+	return nil
+}
+
+// streamReader decrypts a segmented stream.
+type streamReader struct {
+	r       io.Reader
+	aead    cipher.AEAD
+	nonce   []byte
+	counter uint32
+	in      []byte // one sealed segment plus one lookahead byte
+	buf     []byte // plaintext scratch space
+	plain   []byte // decrypted bytes not yet returned
+	final   bool
+	err     error
+}
+
+// newStreamReader reads the stream header from r and returns the reader.
+func newStreamReader(r io.Reader, aead cipher.AEAD) (*streamReader, error) {
+	header := make([]byte, 1+aead.NonceSize()-streamNonceSuffix)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrCiphertextShort
+		}
+		return nil, err
+	}
+	if header[0] != streamVersion {
+		return nil, fmt.Errorf("%w: unsupported stream version %d", ErrDecrypt, header[0])
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce, header[1:])
+
+	return &streamReader{
+		r:     r,
+		aead:  aead,
+		nonce: nonce,
+		in:    make([]byte, 0, streamSegmentSize+aead.Overhead()+1),
+		buf:   make([]byte, 0, streamSegmentSize),
+	}, nil
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	for len(s.plain) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		if s.final {
+			zeroize(s.buf[:cap(s.buf)])
+			return 0, io.EOF
+		}
+		s.err = s.next()
+	}
+
+	n := copy(p, s.plain)
+	s.plain = s.plain[n:]
+	return n, nil
+}
+
+// next reads and opens one segment. A segment is final when the underlying
+// reader ends before the lookahead byte.
+func (s *streamReader) next() error {
+	segLen := streamSegmentSize + s.aead.Overhead()
+
+	n, err := io.ReadFull(s.r, s.in[len(s.in):segLen+1])
+	s.in = s.in[:len(s.in)+n]
+
+	final := false
+	switch {
+	case err == nil:
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		final = true
+	default:
+		return err
+	}
+
+	segment := s.in
+	if !final {
+		segment = s.in[:segLen]
+	}
+	if len(segment) < s.aead.Overhead() {
+		return fmt.Errorf("%w: %w", ErrDecrypt, ErrCiphertextShort)
+	}
+	if s.counter == math.MaxUint32 {
+		return fmt.Errorf("%w: stream too long", ErrDecrypt)
+	}
+
+	setStreamNonce(s.nonce, s.counter, final)
+	plain, err := s.aead.Open(s.buf[:0], s.nonce, segment, nil)
+	if err != nil {
+		return fmt.Errorf("%w: stream segment %d: %w", ErrDecrypt, s.counter, err)
+	}
+
+	s.plain = plain
+	s.final = final
+	s.counter++
+	if !final {
+		// Carry the lookahead byte into the next segment.
+		s.in[0] = s.in[segLen]
+		s.in = s.in[:1]
+	}
+	return nil
+}
+
+// setStreamNonce writes the segment counter and final flag after the prefix.
+func setStreamNonce(nonce []byte, counter uint32, final bool) {
+	suffix := nonce[len(nonce)-streamNonceSuffix:]
+	binary.BigEndian.PutUint32(suffix, counter)
+	suffix[4] = 0
+	if final {
+		suffix[4] = 1
+	}
+}
+
+// xchachaEncryptor is an XChaCha20-Poly1305 aeadEncryptor. Its 19-byte
+// random nonce prefix makes prefix reuse negligible, so unlike AES-GCM it
+// can stream directly under a long-lived key.
+type xchachaEncryptor struct {
+	aeadEncryptor
+}
+
+func (e *xchachaEncryptor) EncryptWriter(_ context.Context, w io.Writer) (io.WriteCloser, error) {
+	return newStreamWriter(w, e.aead)
+}
+
+func (e *xchachaEncryptor) DecryptReader(_ context.Context, r io.Reader) (io.Reader, error) {
+	return newStreamReader(r, e.aead)
+}
+
+// EncryptWriter generates a fresh data key for the stream, bypassing the
+// data-key cache. Format: [2 bytes key len][encrypted key][stream]
+func (e *envelopeEncryptor) EncryptWriter(ctx context.Context, w io.Writer) (io.WriteCloser, error) {
+	dk, err := e.generateDataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroize(dk.key)
+
+	header, err := frameKey(dk.wrapped, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return newStreamWriter(w, dk.aead)
+}
+
+func (e *envelopeEncryptor) DecryptReader(ctx context.Context, r io.Reader) (io.Reader, error) {
+	var keyLen [2]byte
+	if _, err := io.ReadFull(r, keyLen[:]); err != nil {
+		return nil, ErrCiphertextShort
+	}
+	wrapped := make([]byte, binary.BigEndian.Uint16(keyLen[:]))
+	if _, err := io.ReadFull(r, wrapped); err != nil {
+		return nil, ErrCiphertextShort
+	}
+
+	dataAEAD, err := e.decryptionKey(ctx, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return newStreamReader(r, dataAEAD)
+}
+
+// encryptingReader encrypts src as it is read, so a Processor can replace an
+// io.Reader field without reading the payload during Store.
+type encryptingReader struct {
+	src   io.Reader
+	sw    io.WriteCloser // writes into out
+	out   *bytes.Buffer
+	chunk []byte
+	done  bool
+}
+
+// newEncryptingReader returns a reader yielding the encrypted form of src.
+func newEncryptingReader(ctx context.Context, se StreamEncryptor, src io.Reader) (io.Reader, error) {
+	out := &bytes.Buffer{}
+	sw, err := se.EncryptWriter(ctx, out)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptingReader{src: src, sw: sw, out: out, chunk: make([]byte, streamSegmentSize)}, nil
+}
+
+func (r *encryptingReader) Read(p []byte) (int, error) {
+	for r.out.Len() == 0 && !r.done {
+		n, err := r.src.Read(r.chunk)
+		if n > 0 {
+			if _, werr := r.sw.Write(r.chunk[:n]); werr != nil {
+				return 0, werr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			r.done = true
+			zeroize(r.chunk)
+			if err := r.sw.Close(); err != nil {
+				return 0, err
+			}
+		} else if err != nil {
+			return 0, err
+		}
+	}
+
+	if r.out.Len() == 0 {
+		return 0, io.EOF
+	}
+	return r.out.Read(p)
+}
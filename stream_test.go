@@ -0,0 +1,254 @@
+package cereal
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func streamEncryptors(t *testing.T) map[string]Encryptor {
+	t.Helper()
+	key := []byte("32-byte-key-for-aes-256-encrypt!")
+	xchacha, err := XChaCha(key)
+	if err != nil {
+		t.Fatalf("XChaCha() error: %v", err)
+	}
+	envelope, err := Envelope(key)
+	if err != nil {
+		t.Fatalf("Envelope() error: %v", err)
+	}
+	return map[string]Encryptor{"xchacha": xchacha, "envelope": envelope}
+}
+
+func encryptStream(t *testing.T, enc Encryptor, plaintext []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(context.Background(), enc, &buf)
+	if err != nil {
+		t.Fatalf("NewEncryptWriter() error: %v", err)
+	}
+	// Write in uneven pieces to exercise segment boundaries.
+	for len(plaintext) > 0 {
+		n := min(len(plaintext), 10000)
+		if _, err := w.Write(plaintext[:n]); err != nil {
+			t.Fatalf("Write() error: %v", err)
+		}
+		plaintext = plaintext[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func decryptStream(enc Encryptor, ciphertext []byte) ([]byte, error) {
+	r, err := NewDecryptReader(context.Background(), enc, bytes.NewReader(ciphertext))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestStream_RoundTrip(t *testing.T) {
+	sizes := []int{0, 1, streamSegmentSize - 1, streamSegmentSize, streamSegmentSize + 1, 3*streamSegmentSize + 7}
+
+	for name, enc := range streamEncryptors(t) {
+		for _, size := range sizes {
+			plaintext := make([]byte, size)
+			_, _ = rand.Read(plaintext)
+
+			ciphertext := encryptStream(t, enc, plaintext)
+			got, err := decryptStream(enc, ciphertext)
+			if err != nil {
+				t.Fatalf("%s/%d: decrypt error: %v", name, size, err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Errorf("%s/%d: round trip mismatch", name, size)
+			}
+		}
+	}
+}
+
+func TestStream_DetectsTampering(t *testing.T) {
+	enc := streamEncryptors(t)["xchacha"]
+	plaintext := bytes.Repeat([]byte("x"), 2*streamSegmentSize+100)
+	ciphertext := encryptStream(t, enc, plaintext)
+
+	header := 1 + 24 - streamNonceSuffix
+	segLen := streamSegmentSize + 16
+
+	tests := map[string][]byte{
+		"truncated at segment boundary": ciphertext[:header+segLen],
+		"truncated mid segment":         ciphertext[:len(ciphertext)-5],
+		"flipped byte":                  func() []byte { c := bytes.Clone(ciphertext); c[header+10] ^= 1; return c }(),
+		"swapped segments": func() []byte {
+			c := bytes.Clone(ciphertext)
+			copy(c[header:], ciphertext[header+segLen:header+2*segLen])
+			copy(c[header+segLen:], ciphertext[header:header+segLen])
+			return c
+		}(),
+	}
+	for name, tampered := range tests {
+		if _, err := decryptStream(enc, tampered); !errors.Is(err, ErrDecrypt) {
+			t.Errorf("%s: expected ErrDecrypt, got %v", name, err)
+		}
+	}
+
+	if _, err := decryptStream(enc, ciphertext[:3]); !errors.Is(err, ErrCiphertextShort) {
+		t.Errorf("short header: expected ErrCiphertextShort, got %v", err)
+	}
+}
+
+func TestStream_WriteAfterClose(t *testing.T) {
+	w, _ := NewEncryptWriter(context.Background(), streamEncryptors(t)["xchacha"], io.Discard)
+	_ = w.Close()
+	if _, err := w.Write([]byte("late")); !errors.Is(err, errStreamClosed) {
+		t.Errorf("expected errStreamClosed, got %v", err)
+	}
+}
+
+func TestStream_Unsupported(t *testing.T) {
+	priv, _ := rsa.GenerateKey(rand.Reader, 2048)
+	// AES-GCM under a long-lived key has too short a nonce prefix to stream.
+	aes, _ := AES([]byte("32-byte-key-for-aes-256-encrypt!"))
+
+	for name, enc := range map[string]Encryptor{"rsa": RSA(&priv.PublicKey, priv), "aes": aes} {
+		if _, err := NewEncryptWriter(context.Background(), enc, io.Discard); !errors.Is(err, ErrEncryptUnsupported) {
+			t.Errorf("%s: expected ErrEncryptUnsupported, got %v", name, err)
+		}
+		if _, err := NewDecryptReader(context.Background(), enc, strings.NewReader("")); !errors.Is(err, ErrDecryptUnsupported) {
+			t.Errorf("%s: expected ErrDecryptUnsupported, got %v", name, err)
+		}
+	}
+}
+
+func TestStream_HeaderLayout(t *testing.T) {
+	encs := streamEncryptors(t)
+
+	// XChaCha: [version][19-byte random prefix], fresh for every stream.
+	a := encryptStream(t, encs["xchacha"], nil)
+	b := encryptStream(t, encs["xchacha"], nil)
+	const prefix = 24 - streamNonceSuffix
+	if a[0] != streamVersion || len(a) != 1+prefix+16 {
+		t.Fatalf("xchacha stream = %d bytes, version %d", len(a), a[0])
+	}
+	if bytes.Equal(a[1:1+prefix], b[1:1+prefix]) {
+		t.Error("xchacha streams reused a nonce prefix")
+	}
+
+	// Envelope: [2-byte key length][wrapped data key][version][7-byte prefix].
+	// The prefix is short, but each stream has its own data key.
+	c := encryptStream(t, encs["envelope"], nil)
+	d := encryptStream(t, encs["envelope"], nil)
+	keyLen := int(binary.BigEndian.Uint16(c))
+	if len(c) != 2+keyLen+1+12-streamNonceSuffix+16 || c[2+keyLen] != streamVersion {
+		t.Fatalf("envelope stream = %d bytes with %d-byte wrapped key", len(c), keyLen)
+	}
+	if bytes.Equal(c[2:2+keyLen], d[2:2+keyLen]) {
+		t.Error("envelope streams reused a data key")
+	}
+}
+
+// Attachment streams its body through encryption.
+type Attachment struct {
+	Name string        `json:"name"`
+	Body io.Reader     `json:"-" store.encrypt:"envelope" load.decrypt:"envelope"`
+	File io.ReadCloser `json:"-" store.encrypt:"envelope" load.decrypt:"envelope"`
+}
+
+func (a Attachment) Clone() Attachment { return a }
+
+// closeRecorder records whether Close was called.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestProcessor_StreamFields(t *testing.T) {
+	proc, err := NewProcessor[Attachment]()
+	if err != nil {
+		t.Fatalf("NewProcessor() error: %v", err)
+	}
+	proc.SetEncryptor(EncryptEnvelope, streamEncryptors(t)["envelope"])
+
+	body := bytes.Repeat([]byte("attachment "), 20000)
+	file := &closeRecorder{Reader: strings.NewReader("file contents")}
+
+	ctx := context.Background()
+	stored, err := proc.Store(ctx, Attachment{Name: "a.txt", Body: bytes.NewReader(body), File: file})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	ciphertext, err := io.ReadAll(stored.Body)
+	if err != nil {
+		t.Fatalf("reading encrypted body: %v", err)
+	}
+	if bytes.Contains(ciphertext, []byte("attachment")) {
+		t.Error("stored body contains plaintext")
+	}
+	fileCiphertext, _ := io.ReadAll(stored.File)
+	if err := stored.File.Close(); err != nil || !file.closed {
+		t.Error("Close was not forwarded to the original file")
+	}
+
+	loaded, err := proc.Load(ctx, Attachment{
+		Body: bytes.NewReader(ciphertext),
+		File: io.NopCloser(bytes.NewReader(fileCiphertext)),
+	})
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	got, err := io.ReadAll(loaded.Body)
+	if err != nil {
+		t.Fatalf("reading decrypted body: %v", err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Error("body round trip mismatch")
+	}
+	gotFile, _ := io.ReadAll(loaded.File)
+	if string(gotFile) != "file contents" {
+		t.Errorf("File = %q, want %q", gotFile, "file contents")
+	}
+}
+
+func TestProcessor_StreamFieldRequiresStreamEncryptor(t *testing.T) {
+	priv, _ := rsa.GenerateKey(rand.Reader, 2048)
+	proc, _ := NewProcessor[Attachment]()
+	proc.SetEncryptor(EncryptEnvelope, RSA(&priv.PublicKey, priv))
+
+	if err := proc.Validate(); !errors.Is(err, ErrDecryptUnsupported) && !errors.Is(err, ErrEncryptUnsupported) {
+		t.Errorf("expected unsupported error, got %v", err)
+	}
+}
+
+type streamOptionRecord struct {
+	Body io.Reader `store.encrypt:"aes,pad=pow2"`
+}
+
+func (r streamOptionRecord) Clone() streamOptionRecord { return r }
+
+type streamHashRecord struct {
+	Body io.Reader `receive.hash:"sha256"`
+}
+
+func (r streamHashRecord) Clone() streamHashRecord { return r }
+
+func TestProcessor_StreamFieldInvalidTags(t *testing.T) {
+	if _, err := NewProcessor[streamOptionRecord](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("options: expected ErrInvalidTag, got %v", err)
+	}
+	if _, err := NewProcessor[streamHashRecord](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("hash: expected ErrInvalidTag, got %v", err)
+	}
+}
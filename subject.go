@@ -0,0 +1,204 @@
+package cereal
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// subjectKeySize is the size of per-subject AES-256 data keys.
+const subjectKeySize = 32
+
+// subjectContextKey is the context key for the current subject ID.
+type subjectContextKey struct{}
+
+// WithSubject returns a context carrying the subject whose key encrypts the
+// operation's data. The Processor sets it from the field tagged
+// cereal:"subject"; call it directly when encrypting outside a Processor.
+func WithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectContextKey{}, subject)
+}
+
+// SubjectFromContext returns the subject set by WithSubject.
+func SubjectFromContext(ctx context.Context) (string, bool) {
+	subject, ok := ctx.Value(subjectContextKey{}).(string)
+	return subject, ok && subject != ""
+}
+
+// SubjectKeyStore holds one data key per subject for crypto-shredding.
+//
+// Deleting a subject's key makes every ciphertext written under it
+// permanently unreadable, including copies in backups.
+//
+// Both getters return a copy of the key owned by the caller: the Subject
+// encryptor zeroizes it after use, and a store must be free to zeroize its
+// own copy on DeleteKey while a Store is in flight.
+type SubjectKeyStore interface {
+	// GetOrCreateKey returns the subject's data key, generating and
+	// persisting a new one if none exists.
+	GetOrCreateKey(ctx context.Context, subject string) ([]byte, error)
+
+	// GetKey returns the subject's data key. It returns an error wrapping
+	// ErrKeyShredded when the subject has no key.
+	GetKey(ctx context.Context, subject string) ([]byte, error)
+
+	// DeleteKey destroys the subject's data key.
+	DeleteKey(ctx context.Context, subject string) error
+}
+
+// memorySubjectKeyStore keeps subject keys in process memory.
+type memorySubjectKeyStore struct {
+	mu   sync.Mutex
+	keys map[string][]byte
+}
+
+// MemorySubjectKeyStore returns an in-memory SubjectKeyStore.
+// Keys are lost when the process exits; use it for tests and development.
+func MemorySubjectKeyStore() SubjectKeyStore {
+	return &memorySubjectKeyStore{keys: make(map[string][]byte)}
+}
+
+func (s *memorySubjectKeyStore) GetOrCreateKey(_ context.Context, subject string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[subject]; ok {
+		return bytes.Clone(key), nil
+	}
+
+	key := make([]byte, subjectKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("failed to generate subject key: %w", err)
+	}
+	s.keys[subject] = key
+	return bytes.Clone(key), nil
+}
+
+func (s *memorySubjectKeyStore) GetKey(_ context.Context, subject string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[subject]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyShredded, subject)
+	}
+	return bytes.Clone(key), nil
+}
+
+func (s *memorySubjectKeyStore) DeleteKey(_ context.Context, subject string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[subject]; ok {
+		zeroize(key)
+		delete(s.keys, subject)
+	}
+	return nil
+}
+
+// subjectEncryptor encrypts with the data key of the subject in context.
+type subjectEncryptor struct {
+	store SubjectKeyStore
+}
+
+// Subject returns an encryptor that encrypts each value under the data key
+// of the subject in the operation's context.
+//
+// Tag the subject ID field with cereal:"subject" and the Processor passes it
+// through the context. Values are sealed with AES-256-GCM and bound to the
+// subject ID, so ciphertext moved between subjects fails to decrypt. Once
+// the subject's key is deleted from the store, decryption returns
+// ErrKeyShredded.
+//
+// Format: [12 bytes nonce][ciphertext]
+func Subject(store SubjectKeyStore) Encryptor {
+	return &subjectEncryptor{store: store}
+}
+
+func (e *subjectEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	return e.EncryptContext(context.Background(), plaintext)
+}
+
+func (e *subjectEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	return e.DecryptContext(context.Background(), ciphertext)
+}
+
+func (e *subjectEncryptor) EncryptContext(ctx context.Context, plaintext []byte) ([]byte, error) {
+	out, err := e.EncryptBatch(ctx, [][]byte{plaintext})
+	if err != nil {
+		return nil, err
+	}
+	return out[0], nil
+}
+
+func (e *subjectEncryptor) DecryptContext(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	out, err := e.DecryptBatch(ctx, [][]byte{ciphertext})
+	if err != nil {
+		return nil, err
+	}
+	return out[0], nil
+}
+
+// EncryptBatch fetches the subject's key once for all values.
+func (e *subjectEncryptor) EncryptBatch(ctx context.Context, plaintexts [][]byte) ([][]byte, error) {
+	subject, aead, err := e.aead(ctx, e.store.GetOrCreateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([][]byte, len(plaintexts))
+	for i, pt := range plaintexts {
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return nil, err
+		}
+		out[i] = aead.Seal(nonce, nonce, pt, []byte(subject))
+	}
+	return out, nil
+}
+
+// DecryptBatch fetches the subject's key once for all values.
+func (e *subjectEncryptor) DecryptBatch(ctx context.Context, ciphertexts [][]byte) ([][]byte, error) {
+	subject, aead, err := e.aead(ctx, e.store.GetKey)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([][]byte, len(ciphertexts))
+	for i, ct := range ciphertexts {
+		nonceSize := aead.NonceSize()
+		if len(ct) < nonceSize {
+			return nil, ErrCiphertextShort
+		}
+		pt, err := aead.Open(nil, ct[:nonceSize], ct[nonceSize:], []byte(subject))
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to decrypt data: %w", ErrDecrypt, err)
+		}
+		out[i] = pt
+	}
+	return out, nil
+}
+
+// aead returns the subject in ctx and an AEAD keyed with its data key.
+func (e *subjectEncryptor) aead(ctx context.Context, getKey func(context.Context, string) ([]byte, error)) (string, cipher.AEAD, error) {
+	subject, ok := SubjectFromContext(ctx)
+	if !ok {
+		return "", nil, ErrMissingSubject
+	}
+
+	key, err := getKey(ctx, subject)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get subject key: %w", err)
+	}
+	defer zeroize(key)
+
+	aead, err := newAEAD(EncryptAES, key)
+	if err != nil {
+		return "", nil, err
+	}
+	return subject, aead, nil
+}
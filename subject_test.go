@@ -0,0 +1,222 @@
+package cereal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+// SubjectUser keys its encrypted fields by UserID.
+type SubjectUser struct {
+	UserID string   `json:"user_id" cereal:"subject"`
+	Email  string   `json:"email" store.encrypt:"subject" load.decrypt:"subject"`
+	Notes  []string `json:"notes" store.encrypt:"subject" load.decrypt:"subject"`
+}
+
+func (u SubjectUser) Clone() SubjectUser {
+	c := u
+	c.Notes = append([]string(nil), u.Notes...)
+	return c
+}
+
+func newSubjectProcessor(t *testing.T, store SubjectKeyStore) *Processor[SubjectUser] {
+	t.Helper()
+	proc, err := NewProcessor[SubjectUser]()
+	if err != nil {
+		t.Fatalf("NewProcessor() error: %v", err)
+	}
+	proc.SetEncryptor(EncryptSubject, Subject(store))
+	return proc
+}
+
+func TestSubject_RoundTrip(t *testing.T) {
+	proc := newSubjectProcessor(t, MemorySubjectKeyStore())
+	ctx := context.Background()
+
+	original := SubjectUser{UserID: "u-1", Email: testEmail, Notes: []string{"a", "b"}}
+	stored, err := proc.Store(ctx, original)
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	if stored.UserID != "u-1" {
+		t.Errorf("subject field changed: %q", stored.UserID)
+	}
+	if stored.Email == testEmail {
+		t.Error("Email was not encrypted")
+	}
+
+	loaded, err := proc.Load(ctx, stored)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if loaded.Email != testEmail || loaded.Notes[1] != "b" {
+		t.Errorf("round trip mismatch: %+v", loaded)
+	}
+}
+
+func TestSubject_Shredded(t *testing.T) {
+	store := MemorySubjectKeyStore()
+	proc := newSubjectProcessor(t, store)
+	ctx := context.Background()
+
+	alice, err := proc.Store(ctx, SubjectUser{UserID: "alice", Email: testEmail})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	bob, err := proc.Store(ctx, SubjectUser{UserID: "bob", Email: testEmail})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+
+	if err := store.DeleteKey(ctx, "alice"); err != nil {
+		t.Fatalf("DeleteKey() error: %v", err)
+	}
+
+	_, err = proc.Load(ctx, alice)
+	if !errors.Is(err, ErrKeyShredded) {
+		t.Fatalf("expected ErrKeyShredded, got %v", err)
+	}
+	if errors.Is(err, ErrDecrypt) {
+		t.Error("shredded data should not report ErrDecrypt")
+	}
+
+	if _, err := proc.Load(ctx, bob); err != nil {
+		t.Errorf("other subjects should be unaffected: %v", err)
+	}
+}
+
+func TestSubject_BoundToSubject(t *testing.T) {
+	proc := newSubjectProcessor(t, MemorySubjectKeyStore())
+	ctx := context.Background()
+
+	if _, err := proc.Store(ctx, SubjectUser{UserID: "bob", Email: "x"}); err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	alice, err := proc.Store(ctx, SubjectUser{UserID: "alice", Email: testEmail})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+
+	alice.UserID = "bob"
+	if _, err := proc.Load(ctx, alice); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt for ciphertext moved to another subject, got %v", err)
+	}
+}
+
+func TestSubject_MissingSubject(t *testing.T) {
+	proc := newSubjectProcessor(t, MemorySubjectKeyStore())
+
+	_, err := proc.Store(context.Background(), SubjectUser{Email: testEmail})
+	var te *TransformError
+	if !errors.As(err, &te) || !errors.Is(te.Cause, ErrMissingSubject) {
+		t.Errorf("expected ErrMissingSubject cause, got %v", err)
+	}
+}
+
+func TestSubject_WithSubjectContext(t *testing.T) {
+	enc := Subject(MemorySubjectKeyStore()).(EncryptorContext)
+	ctx := WithSubject(context.Background(), "carol")
+
+	ct, err := enc.EncryptContext(ctx, []byte("secret"))
+	if err != nil {
+		t.Fatalf("EncryptContext() error: %v", err)
+	}
+	pt, err := enc.DecryptContext(ctx, ct)
+	if err != nil {
+		t.Fatalf("DecryptContext() error: %v", err)
+	}
+	if string(pt) != "secret" {
+		t.Errorf("DecryptContext() = %q, want %q", pt, "secret")
+	}
+}
+
+type unknownSubjectTag struct {
+	ID string `cereal:"owner"`
+}
+
+func (u unknownSubjectTag) Clone() unknownSubjectTag { return u }
+
+type duplicateSubjectTag struct {
+	A string `cereal:"subject"`
+	B string `cereal:"subject"`
+}
+
+func (u duplicateSubjectTag) Clone() duplicateSubjectTag { return u }
+
+type encryptedSubjectTag struct {
+	ID string `cereal:"subject" store.encrypt:"aes"`
+}
+
+func (u encryptedSubjectTag) Clone() encryptedSubjectTag { return u }
+
+type nonStringSubjectTag struct {
+	ID int `cereal:"subject"`
+}
+
+func (u nonStringSubjectTag) Clone() nonStringSubjectTag { return u }
+
+func TestSubject_InvalidTags(t *testing.T) {
+	if _, err := NewProcessor[unknownSubjectTag](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("unknown value: expected ErrInvalidTag, got %v", err)
+	}
+	if _, err := NewProcessor[duplicateSubjectTag](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("two subjects: expected ErrInvalidTag, got %v", err)
+	}
+	if _, err := NewProcessor[encryptedSubjectTag](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("encrypted subject: expected ErrInvalidTag, got %v", err)
+	}
+	if _, err := NewProcessor[nonStringSubjectTag](); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("non-string subject: expected ErrInvalidTag, got %v", err)
+	}
+}
+
+func TestSubject_ConcurrentDelete(t *testing.T) {
+	store := MemorySubjectKeyStore()
+	enc := Subject(store).(EncryptorContext)
+	ctx := WithSubject(context.Background(), "dave")
+	zeroKey, _ := newAEAD(EncryptAES, make([]byte, subjectKeySize))
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_ = store.DeleteKey(ctx, "dave")
+			}
+		}
+	}()
+
+	for range 500 {
+		ct, err := enc.EncryptContext(ctx, []byte("secret"))
+		if err != nil {
+			t.Fatalf("EncryptContext() error: %v", err)
+		}
+		// Erasure must never leave data sealed under a zeroed key.
+		n := zeroKey.NonceSize()
+		if _, err := zeroKey.Open(nil, ct[:n], ct[n:], []byte("dave")); err == nil {
+			t.Fatal("ciphertext opened with an all-zero key")
+		}
+	}
+	close(stop)
+	<-done
+}
+
+func TestMemorySubjectKeyStore_ReturnsCopy(t *testing.T) {
+	store := MemorySubjectKeyStore()
+	ctx := context.Background()
+
+	key, _ := store.GetOrCreateKey(ctx, "erin")
+	zeroize(key)
+	again, err := store.GetKey(ctx, "erin")
+	if err != nil {
+		t.Fatalf("GetKey() error: %v", err)
+	}
+	if bytes.Equal(again, make([]byte, subjectKeySize)) {
+		t.Error("zeroizing a returned key changed the stored key")
+	}
+}
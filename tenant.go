@@ -0,0 +1,158 @@
+package cereal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// TenantFunc extracts the tenant ID from an operation's context.
+// It returns false when the context carries no tenant.
+type TenantFunc func(ctx context.Context) (string, bool)
+
+// TenantResolver returns the encryptor for a tenant.
+// It should return an error wrapping ErrUnknownTenant for tenants it does not know.
+type TenantResolver func(ctx context.Context, tenant string) (Encryptor, error)
+
+// tenantEncryptor routes each operation to the encryptor of the tenant
+// found in the operation's context.
+type tenantEncryptor struct {
+	tenantOf TenantFunc
+	resolve  TenantResolver
+
+	mu         sync.RWMutex
+	encryptors map[string]Encryptor // nil after Destroy
+}
+
+// Tenant returns an encryptor that selects a per-tenant encryptor from the
+// operation's context.
+//
+// tenantOf reads the tenant ID from ctx. The first operation for a tenant
+// calls resolve and caches the result; later operations reuse it. Resolver
+// errors are not cached, so a failed lookup is retried on the next call.
+//
+// The encryptor fails closed: operations without a tenant in ctx, or for a
+// tenant the resolver rejects, return ErrUnknownTenant. Encrypt and Decrypt
+// have no context and always fail; the Processor uses EncryptContext and
+// DecryptContext.
+func Tenant(tenantOf TenantFunc, resolve TenantResolver) Encryptor {
+	return &tenantEncryptor{
+		tenantOf:   tenantOf,
+		resolve:    resolve,
+		encryptors: make(map[string]Encryptor),
+	}
+}
+
+func (e *tenantEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	return e.EncryptContext(context.Background(), plaintext)
+}
+
+func (e *tenantEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	return e.DecryptContext(context.Background(), ciphertext)
+}
+
+func (e *tenantEncryptor) EncryptContext(ctx context.Context, plaintext []byte) ([]byte, error) {
+	enc, err := e.encryptor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return encryptContext(ctx, enc, plaintext)
+}
+
+func (e *tenantEncryptor) DecryptContext(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	enc, err := e.encryptor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return decryptContext(ctx, enc, ciphertext)
+}
+
+// EncryptBatch resolves the tenant once and forwards the batch, so tenant
+// encryptors that implement BatchEncryptor keep their batching.
+func (e *tenantEncryptor) EncryptBatch(ctx context.Context, plaintexts [][]byte) ([][]byte, error) {
+	enc, err := e.encryptor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if be, ok := enc.(BatchEncryptor); ok {
+		return be.EncryptBatch(ctx, plaintexts)
+	}
+	out := make([][]byte, len(plaintexts))
+	for i, pt := range plaintexts {
+		if out[i], err = encryptContext(batchValueContext(ctx, i), enc, pt); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// DecryptBatch resolves the tenant once and forwards the batch.
+func (e *tenantEncryptor) DecryptBatch(ctx context.Context, ciphertexts [][]byte) ([][]byte, error) {
+	enc, err := e.encryptor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if be, ok := enc.(BatchEncryptor); ok {
+		return be.DecryptBatch(ctx, ciphertexts)
+	}
+	out := make([][]byte, len(ciphertexts))
+	for i, ct := range ciphertexts {
+		if out[i], err = decryptContext(batchValueContext(ctx, i), enc, ct); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// encryptor returns the cached encryptor for the tenant in ctx, resolving
+// it on first use.
+func (e *tenantEncryptor) encryptor(ctx context.Context) (Encryptor, error) {
+	tenant, ok := e.tenantOf(ctx)
+	if !ok || tenant == "" {
+		return nil, fmt.Errorf("%w: no tenant in context", ErrUnknownTenant)
+	}
+
+	e.mu.RLock()
+	enc, ok := e.encryptors[tenant]
+	destroyed := e.encryptors == nil
+	e.mu.RUnlock()
+	if ok {
+		return enc, nil
+	}
+	if destroyed {
+		return nil, ErrClosed
+	}
+
+	// Resolve outside the lock so a slow key service does not block other tenants.
+	enc, err := e.resolve(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if enc == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTenant, tenant)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.encryptors == nil {
+		return nil, ErrClosed
+	}
+	if existing, ok := e.encryptors[tenant]; ok {
+		return existing, nil
+	}
+	e.encryptors[tenant] = enc
+	return enc, nil
+}
+
+// Destroy destroys every cached tenant encryptor that implements Destroyer
+// and drops the cache.
+func (e *tenantEncryptor) Destroy() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, enc := range e.encryptors {
+		if d, ok := enc.(Destroyer); ok {
+			d.Destroy()
+		}
+	}
+	e.encryptors = nil
+}
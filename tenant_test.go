@@ -0,0 +1,157 @@
+package cereal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func tenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok
+}
+
+func withTenant(tenant string) context.Context {
+	return context.WithValue(context.Background(), tenantKey{}, tenant)
+}
+
+// tenantKeys resolves AES encryptors from a fixed key table and counts lookups.
+type tenantKeys struct {
+	keys    map[string][]byte
+	lookups map[string]int
+}
+
+func newTenantKeys() *tenantKeys {
+	return &tenantKeys{
+		keys: map[string][]byte{
+			"acme":   []byte("acme-key-for-aes-256-encryption!"),
+			"globex": []byte("globex-key-for-aes-256-encrypt!!"),
+		},
+		lookups: make(map[string]int),
+	}
+}
+
+func (k *tenantKeys) resolve(_ context.Context, tenant string) (Encryptor, error) {
+	k.lookups[tenant]++
+	key, ok := k.keys[tenant]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, tenant)
+	}
+	return AES(key)
+}
+
+func TestTenant_RoundTrip(t *testing.T) {
+	keys := newTenantKeys()
+	enc := Tenant(tenantFromContext, keys.resolve).(EncryptorContext)
+
+	ctx := withTenant("acme")
+	ct, err := enc.EncryptContext(ctx, []byte("secret"))
+	if err != nil {
+		t.Fatalf("EncryptContext() error: %v", err)
+	}
+	pt, err := enc.DecryptContext(ctx, ct)
+	if err != nil {
+		t.Fatalf("DecryptContext() error: %v", err)
+	}
+	if string(pt) != "secret" {
+		t.Errorf("DecryptContext() = %q, want %q", pt, "secret")
+	}
+	if keys.lookups["acme"] != 1 {
+		t.Errorf("resolver called %d times, want 1", keys.lookups["acme"])
+	}
+}
+
+func TestTenant_IsolatesTenants(t *testing.T) {
+	enc := Tenant(tenantFromContext, newTenantKeys().resolve).(EncryptorContext)
+
+	ct, err := enc.EncryptContext(withTenant("acme"), []byte("secret"))
+	if err != nil {
+		t.Fatalf("EncryptContext() error: %v", err)
+	}
+	if _, err := enc.DecryptContext(withTenant("globex"), ct); err == nil {
+		t.Error("expected another tenant's key to fail decryption")
+	}
+}
+
+func TestTenant_FailsClosed(t *testing.T) {
+	keys := newTenantKeys()
+	enc := Tenant(tenantFromContext, keys.resolve)
+
+	t.Run("no tenant", func(t *testing.T) {
+		if _, err := enc.Encrypt([]byte("secret")); !errors.Is(err, ErrUnknownTenant) {
+			t.Errorf("expected ErrUnknownTenant, got %v", err)
+		}
+	})
+
+	t.Run("unknown tenant", func(t *testing.T) {
+		ec := enc.(EncryptorContext)
+		for i := 0; i < 2; i++ {
+			if _, err := ec.EncryptContext(withTenant("initech"), []byte("secret")); !errors.Is(err, ErrUnknownTenant) {
+				t.Errorf("expected ErrUnknownTenant, got %v", err)
+			}
+		}
+		if keys.lookups["initech"] != 2 {
+			t.Errorf("resolver called %d times, want 2 (errors are not cached)", keys.lookups["initech"])
+		}
+	})
+
+	t.Run("nil encryptor", func(t *testing.T) {
+		nilEnc := Tenant(tenantFromContext, func(context.Context, string) (Encryptor, error) {
+			return nil, nil
+		}).(EncryptorContext)
+		if _, err := nilEnc.EncryptContext(withTenant("acme"), []byte("secret")); !errors.Is(err, ErrUnknownTenant) {
+			t.Errorf("expected ErrUnknownTenant, got %v", err)
+		}
+	})
+}
+
+func TestTenant_Processor(t *testing.T) {
+	proc, _ := NewProcessor[EncryptUser]()
+	proc.SetEncryptor(EncryptAES, Tenant(tenantFromContext, newTenantKeys().resolve))
+
+	stored, err := proc.Store(withTenant("acme"), EncryptUser{ID: "1", Email: testEmail})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	loaded, err := proc.Load(withTenant("acme"), stored)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if loaded.Email != testEmail {
+		t.Errorf("Email = %q, want %q", loaded.Email, testEmail)
+	}
+
+	_, err = proc.Store(context.Background(), EncryptUser{ID: "1", Email: testEmail})
+	var te *TransformError
+	if !errors.As(err, &te) || !errors.Is(te.Cause, ErrUnknownTenant) {
+		t.Errorf("expected ErrUnknownTenant cause, got %v", err)
+	}
+}
+
+func TestTenant_Destroy(t *testing.T) {
+	master, _ := Derived(derivedMasterKey)
+	enc := Tenant(tenantFromContext, func(context.Context, string) (Encryptor, error) {
+		return master, nil
+	})
+
+	ctx := WithField(withTenant("acme"), "User", "Email")
+	if _, err := encryptContext(ctx, enc, []byte("secret")); err != nil {
+		t.Fatalf("EncryptContext() error: %v", err)
+	}
+
+	d, ok := enc.(Destroyer)
+	if !ok {
+		t.Fatal("tenant encryptor should implement Destroyer")
+	}
+	d.Destroy()
+
+	if master.(*derivedEncryptor).masterKey != nil {
+		t.Error("cached tenant encryptor should be destroyed")
+	}
+	if _, err := encryptContext(ctx, enc, []byte("secret")); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed after Destroy, got %v", err)
+	}
+}
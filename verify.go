@@ -0,0 +1,250 @@
+package cereal
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Verifier is implemented by password hashers that can check a plaintext
+// against a hash they produced. Argon2 and Bcrypt hashers implement it.
+type Verifier interface {
+	// Verify reports whether plaintext matches encoded. A mismatch returns
+	// false with a nil error; a malformed encoding returns ErrInvalidHash.
+	Verify(encoded string, plaintext []byte) (bool, error)
+
+	// NeedsRehash reports whether encoded was produced with parameters
+	// other than the hasher's current ones, so the caller can replace it
+	// after a successful Verify.
+	NeedsRehash(encoded string) (bool, error)
+}
+
+// VerifierContext is implemented by verifiers that honor the operation's
+// context. Processor.Verify prefers VerifyContext over Verify when available.
+type VerifierContext interface {
+	// VerifyContext reports whether plaintext matches encoded.
+	VerifyContext(ctx context.Context, encoded string, plaintext []byte) (bool, error)
+}
+
+// verifyContext verifies with v, preferring VerifierContext.
+func verifyContext(ctx context.Context, v Verifier, encoded string, plaintext []byte) (bool, error) {
+	if vc, ok := v.(VerifierContext); ok {
+		return vc.VerifyContext(ctx, encoded, plaintext)
+	}
+	return v.Verify(encoded, plaintext)
+}
+
+// argon2MaxParamFactor bounds the cost of a stored argon2 hash relative to
+// the verifying hasher: memory, time, and threads may each be at most this
+// multiple of the current parameters. Hashes from before a moderate
+// downgrade still verify, while a crafted hash cannot make Verify allocate
+// arbitrary memory.
+const argon2MaxParamFactor = 4
+
+// bcryptMaxCostMargin bounds the cost of a stored bcrypt hash relative to
+// the verifying hasher. Each step doubles the work, so this matches the
+// argon2MaxParamFactor of four.
+const bcryptMaxCostMargin = 2
+
+// argon2Encoded is a parsed $argon2id$ PHC string.
+type argon2Encoded struct {
+	version int
+	params  Argon2Params
+	salt    []byte
+	hash    []byte
+}
+
+// parseArgon2 parses $argon2id$v=19$m=65536,t=1,p=4$<salt>$<hash>.
+func parseArgon2(encoded string) (*argon2Encoded, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return nil, fmt.Errorf("%w: not an argon2id hash", ErrInvalidHash)
+	}
+
+	var e argon2Encoded
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &e.version); err != nil {
+		return nil, fmt.Errorf("%w: argon2 version: %w", ErrInvalidHash, err)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &e.params.Memory, &e.params.Time, &e.params.Threads); err != nil {
+		return nil, fmt.Errorf("%w: argon2 parameters: %w", ErrInvalidHash, err)
+	}
+	// argon2.IDKey panics on zero time or parallelism
+	if e.params.Time == 0 || e.params.Threads == 0 {
+		return nil, fmt.Errorf("%w: argon2 parameters out of range", ErrInvalidHash)
+	}
+
+	var err error
+	if e.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("%w: argon2 salt: %w", ErrInvalidHash, err)
+	}
+	if e.hash, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, fmt.Errorf("%w: argon2 hash: %w", ErrInvalidHash, err)
+	}
+	if len(e.salt) == 0 || len(e.hash) == 0 {
+		return nil, fmt.Errorf("%w: empty argon2 salt or hash", ErrInvalidHash)
+	}
+	e.params.SaltLen = uint32(len(e.salt)) // #nosec G115 -- bounded by the encoded string length
+	e.params.KeyLen = uint32(len(e.hash))  // #nosec G115 -- bounded by the encoded string length
+	return &e, nil
+}
+
+// withinBounds reports whether params cost at most argon2MaxParamFactor
+// times the hasher's own in memory, time, and threads.
+func (h *argon2Hasher) withinBounds(params Argon2Params) bool {
+	return uint64(params.Memory) <= argon2MaxParamFactor*uint64(h.params.Memory) &&
+		uint64(params.Time) <= argon2MaxParamFactor*uint64(h.params.Time) &&
+		uint64(params.Threads) <= argon2MaxParamFactor*uint64(h.params.Threads)
+}
+
+// Verify recomputes the hash with the parameters and salt stored in
+// encoded and compares it in constant time. Parameters above
+// argon2MaxParamFactor times the hasher's are rejected with ErrInvalidHash
+// rather than run; NeedsRehash reports them.
+func (h *argon2Hasher) Verify(encoded string, plaintext []byte) (bool, error) {
+	e, err := parseArgon2(encoded)
+	if err != nil {
+		return false, err
+	}
+	if e.version != argon2.Version {
+		return false, fmt.Errorf("%w: unsupported argon2 version %d", ErrInvalidHash, e.version)
+	}
+	if !h.withinBounds(e.params) {
+		return false, fmt.Errorf("%w: argon2 parameters m=%d,t=%d,p=%d exceed the hasher's limit",
+			ErrInvalidHash, e.params.Memory, e.params.Time, e.params.Threads)
+	}
+
+	hash := argon2.IDKey(plaintext, e.salt, e.params.Time, e.params.Memory, e.params.Threads, e.params.KeyLen)
+	return subtle.ConstantTimeCompare(hash, e.hash) == 1, nil
+}
+
+// NeedsRehash reports whether encoded differs from the hasher's parameters
+// or the current argon2 version, including hashes too costly to Verify.
+func (h *argon2Hasher) NeedsRehash(encoded string) (bool, error) {
+	e, err := parseArgon2(encoded)
+	if err != nil {
+		return false, err
+	}
+	return e.version != argon2.Version || e.params != h.params, nil
+}
+
+// verifyMemoryCost returns the memory verifying encoded uses, in bytes: its
+// own m= parameter, or the hasher's if encoded will be rejected unrun.
+func (h *argon2Hasher) verifyMemoryCost(encoded string) int64 {
+	e, err := parseArgon2(encoded)
+	if err != nil || !h.withinBounds(e.params) {
+		return h.MemoryCost()
+	}
+	return int64(e.params.Memory) * 1024
+}
+
+// Verify compares plaintext with a bcrypt hash in constant time. Costs
+// above the hasher's plus bcryptMaxCostMargin are rejected with
+// ErrInvalidHash rather than run; NeedsRehash reports them.
+func (h *bcryptHasher) Verify(encoded string, plaintext []byte) (bool, error) {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidHash, err)
+	}
+	if cost > h.cost+bcryptMaxCostMargin {
+		return false, fmt.Errorf("%w: bcrypt cost %d exceeds the hasher's limit", ErrInvalidHash, cost)
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(encoded), plaintext)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	default:
+		return false, fmt.Errorf("%w: %w", ErrInvalidHash, err)
+	}
+}
+
+// NeedsRehash reports whether encoded was hashed with a different cost,
+// including costs too high to Verify.
+func (h *bcryptHasher) NeedsRehash(encoded string) (bool, error) {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidHash, err)
+	}
+	return cost != h.cost, nil
+}
+
+// Verify checks plaintext against the hash stored in obj's field, named
+// as in errors (e.g. "Password" or "Account.Password"). The field must be
+// a string or []byte with a receive.hash tag whose hasher implements
+// Verifier; otherwise a ConfigError is returned. A field behind a nil
+// pointer never matches.
+//
+//	ok, err := proc.Verify(ctx, user, "Password", []byte(attempt))
+func (p *Processor[T]) Verify(ctx context.Context, obj T, field string, plaintext []byte) (bool, error) {
+	p.inflight.RLock()
+	defer p.inflight.RUnlock()
+
+	v, encoded, ok, err := p.storedHash(obj, field)
+	if err != nil || !ok {
+		return false, err
+	}
+	return verifyContext(ctx, v, encoded, plaintext)
+}
+
+// NeedsRehash reports whether the hash stored in obj's field was produced
+// with parameters other than its hasher's current ones. Call it after a
+// successful Verify and, if true, hash the plaintext again with Receive.
+func (p *Processor[T]) NeedsRehash(obj T, field string) (bool, error) {
+	p.inflight.RLock()
+	defer p.inflight.RUnlock()
+
+	v, encoded, ok, err := p.storedHash(obj, field)
+	if err != nil || !ok {
+		return false, err
+	}
+	return v.NeedsRehash(encoded)
+}
+
+// storedHash finds the receive.hash field named field, its Verifier, and
+// its value in obj. ok is false if the field sits behind a nil pointer.
+// Caller must hold p.inflight.
+func (p *Processor[T]) storedHash(obj T, field string) (v Verifier, encoded string, ok bool, err error) {
+	cfg, err := p.validatedConfig()
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	var plan *processorFieldPlan
+	for i := range p.receivePlans.hashFields {
+		if p.receivePlans.hashFields[i].name == field {
+			plan = &p.receivePlans.hashFields[i]
+			break
+		}
+	}
+	if plan == nil || plan.isSlice || plan.isMap {
+		return nil, "", false, newConfigError(
+			fmt.Errorf("%w: no string receive.hash field", ErrInvalidTag), "", field)
+	}
+
+	hasher, found := cfg.hashers[HashAlgo(plan.tagVal)]
+	if !found {
+		return nil, "", false, newConfigError(ErrMissingHasher, plan.tagVal, plan.name)
+	}
+	v, found = hasher.(Verifier)
+	if !found {
+		return nil, "", false, newConfigError(ErrVerifyUnsupported, plan.tagVal, plan.name)
+	}
+
+	fv, found := p.getField(reflect.ValueOf(&obj).Elem(), *plan)
+	if !found {
+		return nil, "", false, nil
+	}
+	if plan.isBytes {
+		return v, string(fv.Bytes()), true, nil
+	}
+	return v, fv.String(), true, nil
+}
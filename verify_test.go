@@ -0,0 +1,282 @@
+package cereal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testArgon2Params keeps argon2 tests fast.
+var testArgon2Params = Argon2Params{Time: 1, Memory: 1024, Threads: 1, KeyLen: 32, SaltLen: 16}
+
+func TestArgon2_Verify(t *testing.T) {
+	h := Argon2WithParams(testArgon2Params)
+	encoded, err := h.Hash([]byte("hunter2"))
+	if err != nil {
+		t.Fatalf("Hash() error: %v", err)
+	}
+	v := h.(Verifier)
+
+	if ok, err := v.Verify(encoded, []byte("hunter2")); !ok || err != nil {
+		t.Errorf("Verify(correct) = %v, %v", ok, err)
+	}
+	if ok, err := v.Verify(encoded, []byte("hunter3")); ok || err != nil {
+		t.Errorf("Verify(wrong) = %v, %v", ok, err)
+	}
+
+	// Parameters come from the encoding, not the verifying hasher.
+	if ok, _ := Argon2().(Verifier).Verify(encoded, []byte("hunter2")); !ok {
+		t.Error("Verify() with different current parameters should still match")
+	}
+
+	for _, bad := range []string{
+		"",
+		"$2a$10$abcdefghijklmnopqrstuu",
+		"$argon2id$v=19$m=1024,t=0,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$",
+		"$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$aGFzaA",
+		strings.Replace(encoded, "m=1024", "m=x", 1),
+	} {
+		if _, err := v.Verify(bad, []byte("hunter2")); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("Verify(%q) error = %v, want ErrInvalidHash", bad, err)
+		}
+	}
+}
+
+func TestArgon2_NeedsRehash(t *testing.T) {
+	h := Argon2WithParams(testArgon2Params)
+	encoded, _ := h.Hash([]byte("hunter2"))
+
+	if rehash, err := h.(Verifier).NeedsRehash(encoded); rehash || err != nil {
+		t.Errorf("NeedsRehash(same params) = %v, %v", rehash, err)
+	}
+
+	stronger := testArgon2Params
+	stronger.Time = 2
+	if rehash, _ := Argon2WithParams(stronger).(Verifier).NeedsRehash(encoded); !rehash {
+		t.Error("NeedsRehash() should report changed time")
+	}
+	longer := testArgon2Params
+	longer.KeyLen = 64
+	if rehash, _ := Argon2WithParams(longer).(Verifier).NeedsRehash(encoded); !rehash {
+		t.Error("NeedsRehash() should report changed key length")
+	}
+
+	if _, err := h.(Verifier).NeedsRehash("not a hash"); !errors.Is(err, ErrInvalidHash) {
+		t.Errorf("NeedsRehash(invalid) error = %v, want ErrInvalidHash", err)
+	}
+}
+
+func TestArgon2_VerifyBoundsParams(t *testing.T) {
+	h := Argon2WithParams(testArgon2Params)
+	encoded, _ := h.Hash([]byte("hunter2"))
+	v := h.(Verifier)
+
+	// Up to argon2MaxParamFactor times the current parameters still verify.
+	heavier := testArgon2Params
+	heavier.Memory *= argon2MaxParamFactor
+	old, _ := Argon2WithParams(heavier).Hash([]byte("hunter2"))
+	if ok, err := v.Verify(old, []byte("hunter2")); !ok || err != nil {
+		t.Errorf("Verify(within bound) = %v, %v", ok, err)
+	}
+
+	for _, bad := range []string{
+		strings.Replace(encoded, "m=1024", "m=4194304", 1),
+		strings.Replace(encoded, "t=1", "t=1000000", 1),
+		strings.Replace(encoded, "p=1", "p=255", 1),
+	} {
+		if _, err := v.Verify(bad, []byte("hunter2")); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("Verify(%q) error = %v, want ErrInvalidHash", bad, err)
+		}
+		if rehash, err := v.NeedsRehash(bad); !rehash || err != nil {
+			t.Errorf("NeedsRehash(%q) = %v, %v, want true", bad, rehash, err)
+		}
+	}
+}
+
+func TestBcrypt_Verify(t *testing.T) {
+	h := BcryptWithCost(BcryptMinCost)
+	encoded, err := h.Hash([]byte("hunter2"))
+	if err != nil {
+		t.Fatalf("Hash() error: %v", err)
+	}
+	v := h.(Verifier)
+
+	if ok, err := v.Verify(encoded, []byte("hunter2")); !ok || err != nil {
+		t.Errorf("Verify(correct) = %v, %v", ok, err)
+	}
+	if ok, err := v.Verify(encoded, []byte("hunter3")); ok || err != nil {
+		t.Errorf("Verify(wrong) = %v, %v", ok, err)
+	}
+	if _, err := v.Verify("$argon2id$v=19$", []byte("hunter2")); !errors.Is(err, ErrInvalidHash) {
+		t.Errorf("Verify(invalid) error = %v, want ErrInvalidHash", err)
+	}
+
+	if rehash, err := v.NeedsRehash(encoded); rehash || err != nil {
+		t.Errorf("NeedsRehash(same cost) = %v, %v", rehash, err)
+	}
+	if rehash, _ := BcryptWithCost(BcryptMinCost + 1).(Verifier).NeedsRehash(encoded); !rehash {
+		t.Error("NeedsRehash() should report changed cost")
+	}
+	if _, err := v.NeedsRehash("not a hash"); !errors.Is(err, ErrInvalidHash) {
+		t.Errorf("NeedsRehash(invalid) error = %v, want ErrInvalidHash", err)
+	}
+}
+
+func TestBcrypt_VerifyBoundsCost(t *testing.T) {
+	h := BcryptWithCost(BcryptMinCost)
+	v := h.(Verifier)
+
+	// Up to bcryptMaxCostMargin above the current cost still verifies.
+	old, _ := BcryptWithCost(BcryptMinCost + bcryptMaxCostMargin).Hash([]byte("hunter2"))
+	if ok, err := v.Verify(old, []byte("hunter2")); !ok || err != nil {
+		t.Errorf("Verify(within bound) = %v, %v", ok, err)
+	}
+
+	// A crafted cost of 31 would run 2^31 rounds; it is rejected unrun.
+	encoded, _ := h.Hash([]byte("hunter2"))
+	bad := strings.Replace(encoded, fmt.Sprintf("$%02d$", BcryptMinCost), "$31$", 1)
+	if _, err := v.Verify(bad, []byte("hunter2")); !errors.Is(err, ErrInvalidHash) {
+		t.Errorf("Verify(cost 31) error = %v, want ErrInvalidHash", err)
+	}
+	if rehash, err := v.NeedsRehash(bad); !rehash || err != nil {
+		t.Errorf("NeedsRehash(cost 31) = %v, %v, want true", rehash, err)
+	}
+}
+
+// VerifyAccount nests a hashed field behind a pointer.
+type VerifyAccount struct {
+	Password []byte `json:"password" receive.hash:"argon2"`
+}
+
+type VerifyUser struct {
+	ID       string         `json:"id"`
+	Password string         `json:"password" receive.hash:"argon2"`
+	Token    string         `json:"token" receive.hash:"sha256"`
+	Backup   []string       `json:"backup" receive.hash:"argon2"`
+	Account  *VerifyAccount `json:"account"`
+}
+
+func (u VerifyUser) Clone() VerifyUser {
+	c := u
+	c.Backup = append([]string(nil), u.Backup...)
+	if u.Account != nil {
+		a := *u.Account
+		a.Password = append([]byte(nil), u.Account.Password...)
+		c.Account = &a
+	}
+	return c
+}
+
+func TestProcessor_Verify(t *testing.T) {
+	ctx := context.Background()
+	proc, _ := NewProcessor[VerifyUser]()
+	proc.SetHasher(HashArgon2, Argon2WithParams(testArgon2Params))
+
+	user, err := proc.Receive(ctx, VerifyUser{
+		Password: "hunter2",
+		Token:    "tok",
+		Account:  &VerifyAccount{Password: []byte("s3cret")},
+	})
+	if err != nil {
+		t.Fatalf("Receive() error: %v", err)
+	}
+
+	if ok, err := proc.Verify(ctx, user, "Password", []byte("hunter2")); !ok || err != nil {
+		t.Errorf("Verify(correct) = %v, %v", ok, err)
+	}
+	if ok, err := proc.Verify(ctx, user, "Password", []byte("hunter3")); ok || err != nil {
+		t.Errorf("Verify(wrong) = %v, %v", ok, err)
+	}
+	if ok, err := proc.Verify(ctx, user, "Account.Password", []byte("s3cret")); !ok || err != nil {
+		t.Errorf("Verify(nested) = %v, %v", ok, err)
+	}
+	if ok, err := proc.Verify(ctx, VerifyUser{}, "Account.Password", []byte("s3cret")); ok || err != nil {
+		t.Errorf("Verify(nil pointer) = %v, %v", ok, err)
+	}
+
+	var ce *ConfigError
+	if _, err := proc.Verify(ctx, user, "Token", []byte("tok")); !errors.Is(err, ErrVerifyUnsupported) || !errors.As(err, &ce) {
+		t.Errorf("Verify(sha256) error = %v, want ErrVerifyUnsupported", err)
+	}
+	for _, field := range []string{"ID", "Backup", "Missing"} {
+		if _, err := proc.Verify(ctx, user, field, nil); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("Verify(%s) error = %v, want ErrInvalidTag", field, err)
+		}
+	}
+}
+
+func TestProcessor_NeedsRehash(t *testing.T) {
+	ctx := context.Background()
+	proc, _ := NewProcessor[VerifyUser]()
+	proc.SetHasher(HashArgon2, Argon2WithParams(testArgon2Params))
+
+	user, _ := proc.Receive(ctx, VerifyUser{Password: "hunter2"})
+	if rehash, err := proc.NeedsRehash(user, "Password"); rehash || err != nil {
+		t.Errorf("NeedsRehash() = %v, %v", rehash, err)
+	}
+
+	// Raising the parameters flags hashes made with the old ones.
+	stronger := testArgon2Params
+	stronger.Time = 2
+	proc.SetHasher(HashArgon2, Argon2WithParams(stronger))
+	if rehash, err := proc.NeedsRehash(user, "Password"); !rehash || err != nil {
+		t.Errorf("NeedsRehash() after upgrade = %v, %v", rehash, err)
+	}
+	if ok, _ := proc.Verify(ctx, user, "Password", []byte("hunter2")); !ok {
+		t.Error("Verify() should match hashes made with old parameters")
+	}
+}
+
+func TestProcessor_VerifyLimited(t *testing.T) {
+	limiter, _ := NewHashLimiter(HashLimiterConfig{Budget: 1 << 20})
+	proc, _ := NewProcessor[VerifyUser]()
+	proc.SetHasher(HashArgon2, limiter.Limit(Argon2WithParams(testArgon2Params)))
+
+	user, _ := proc.Receive(context.Background(), VerifyUser{Password: "hunter2"})
+
+	// Hold the whole budget so verification has to queue.
+	if err := limiter.acquire(context.Background(), 1<<20); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := proc.Verify(ctx, user, "Password", []byte("hunter2")); !errors.Is(err, ErrHashSaturated) {
+		t.Errorf("Verify() over budget = %v, want ErrHashSaturated", err)
+	}
+	limiter.release(1 << 20)
+
+	if ok, err := proc.Verify(context.Background(), user, "Password", []byte("hunter2")); !ok || err != nil {
+		t.Errorf("Verify() = %v, %v", ok, err)
+	}
+}
+
+func TestProcessor_VerifyLimitedWeighsStoredHash(t *testing.T) {
+	heavier := testArgon2Params
+	heavier.Memory *= argon2MaxParamFactor // 4 MiB
+	limiter, _ := NewHashLimiter(HashLimiterConfig{Budget: 8 << 20})
+	proc, _ := NewProcessor[VerifyUser]()
+	proc.SetHasher(HashArgon2, Argon2WithParams(heavier))
+	user, _ := proc.Receive(context.Background(), VerifyUser{Password: "hunter2"})
+
+	// The current hasher costs 1 MiB, but the stored hash needs 4 MiB.
+	proc.SetHasher(HashArgon2, limiter.Limit(Argon2WithParams(testArgon2Params)))
+	if err := limiter.acquire(context.Background(), 6<<20); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := proc.Verify(ctx, user, "Password", []byte("hunter2")); !errors.Is(err, ErrHashSaturated) {
+		t.Errorf("Verify() with 2 MiB free = %v, want ErrHashSaturated", err)
+	}
+	limiter.release(6 << 20)
+
+	// A stored hash larger than the budget is weighed at the budget.
+	small, _ := NewHashLimiter(HashLimiterConfig{Budget: 2 << 20})
+	proc.SetHasher(HashArgon2, small.Limit(Argon2WithParams(testArgon2Params)))
+	if ok, err := proc.Verify(context.Background(), user, "Password", []byte("hunter2")); !ok || err != nil {
+		t.Errorf("Verify() over budget = %v, %v", ok, err)
+	}
+}
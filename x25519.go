@@ -0,0 +1,137 @@
+package cereal
+
+import (
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// x25519Info binds derived keys to this scheme and format version.
+const x25519Info = "cereal x25519 chacha20poly1305 v1"
+
+// x25519Encryptor implements ECIES with ephemeral X25519 key agreement,
+// HKDF-SHA256 key derivation, and ChaCha20-Poly1305.
+type x25519Encryptor struct {
+	pub  *ecdh.PublicKey
+	priv *ecdh.PrivateKey
+}
+
+// X25519 returns an ECIES encryptor using X25519 key agreement.
+// pub is required for encryption; priv is required for decryption.
+// If priv is provided, pub may be nil and is derived from priv.
+//
+// Pass only the public key for write-only services: they can encrypt on
+// store but never decrypt, and Validate rejects load.decrypt fields bound
+// to such an encryptor.
+//
+// Each message uses a fresh ephemeral key pair, so ciphertexts are
+// unlinkable and carry no nonce reuse risk.
+// Format: [32 bytes ephemeral public key][ChaCha20-Poly1305 ciphertext]
+func X25519(pub *ecdh.PublicKey, priv *ecdh.PrivateKey) (Encryptor, error) {
+	if pub == nil && priv == nil {
+		return nil, fmt.Errorf("%w: public or private key required", ErrInvalidKey)
+	}
+
+	if priv != nil {
+		if priv.Curve() != ecdh.X25519() {
+			return nil, fmt.Errorf("%w: private key is not an X25519 key", ErrInvalidKey)
+		}
+		if pub == nil {
+			pub = priv.PublicKey()
+		} else if !pub.Equal(priv.PublicKey()) {
+			return nil, fmt.Errorf("%w: public key does not match private key", ErrInvalidKey)
+		}
+	}
+
+	if pub.Curve() != ecdh.X25519() {
+		return nil, fmt.Errorf("%w: public key is not an X25519 key", ErrInvalidKey)
+	}
+
+	return &x25519Encryptor{pub: pub, priv: priv}, nil
+}
+
+func (e *x25519Encryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := ephemeral.ECDH(e.pub)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeralPub := ephemeral.PublicKey().Bytes()
+	aead, err := e.deriveAEAD(shared, ephemeralPub)
+	if err != nil {
+		return nil, err
+	}
+
+	// The derived key is unique per message, so a zero nonce is safe
+	nonce := make([]byte, aead.NonceSize())
+	return aead.Seal(ephemeralPub, nonce, plaintext, nil), nil
+}
+
+func (e *x25519Encryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	if e.priv == nil {
+		return nil, fmt.Errorf("%w: private key required", ErrDecryptUnsupported)
+	}
+
+	if len(ciphertext) < 32 {
+		return nil, ErrCiphertextShort
+	}
+
+	ephemeralPub, sealed := ciphertext[:32], ciphertext[32:]
+	ephemeral, err := ecdh.X25519().NewPublicKey(ephemeralPub)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid ephemeral key: %w", ErrDecrypt, err)
+	}
+
+	shared, err := e.priv.ECDH(ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecrypt, err)
+	}
+
+	aead, err := e.deriveAEAD(shared, ephemeralPub)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	plaintext, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecrypt, err)
+	}
+
+	return plaintext, nil
+}
+
+// deriveAEAD derives the per-message AEAD from the shared secret.
+// The salt binds both the ephemeral and recipient public keys.
+// The shared secret and derived key are zeroized once the AEAD holds its copy.
+func (e *x25519Encryptor) deriveAEAD(shared, ephemeralPub []byte) (cipher.AEAD, error) {
+	defer zeroize(shared)
+
+	salt := make([]byte, 0, 64)
+	salt = append(salt, ephemeralPub...)
+	salt = append(salt, e.pub.Bytes()...)
+
+	key, err := hkdf.Key(sha256.New, shared, salt, x25519Info, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroize(key)
+
+	return chacha20poly1305.New(key)
+}
+
+// CanEncrypt reports whether a public key is configured.
+func (e *x25519Encryptor) CanEncrypt() bool { return e.pub != nil }
+
+// CanDecrypt reports whether a private key is configured.
+func (e *x25519Encryptor) CanDecrypt() bool { return e.priv != nil }
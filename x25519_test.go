@@ -0,0 +1,170 @@
+package cereal
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func TestX25519_RoundTrip(t *testing.T) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error: %v", err)
+	}
+
+	enc, err := X25519(nil, priv)
+	if err != nil {
+		t.Fatalf("X25519() error: %v", err)
+	}
+
+	plaintext := []byte("hello, world!")
+	ciphertext, err := enc.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+
+	decrypted, err := enc.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt() error: %v", err)
+	}
+
+	if !bytes.Equal(plaintext, decrypted) {
+		t.Errorf("round-trip failed: got %q, want %q", decrypted, plaintext)
+	}
+}
+
+func TestX25519_PublicKeyOnly(t *testing.T) {
+	priv, _ := ecdh.X25519().GenerateKey(rand.Reader)
+
+	writer, err := X25519(priv.PublicKey(), nil)
+	if err != nil {
+		t.Fatalf("X25519() error: %v", err)
+	}
+	reader, _ := X25519(nil, priv)
+
+	ciphertext, err := writer.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+
+	if _, err := writer.Decrypt(ciphertext); !errors.Is(err, ErrDecryptUnsupported) {
+		t.Errorf("expected ErrDecryptUnsupported, got %v", err)
+	}
+
+	decrypted, err := reader.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt() error: %v", err)
+	}
+	if string(decrypted) != "secret" {
+		t.Errorf("Decrypt() = %q, want %q", decrypted, "secret")
+	}
+}
+
+func TestX25519_DifferentEphemeralKeys(t *testing.T) {
+	priv, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	enc, _ := X25519(nil, priv)
+
+	c1, _ := enc.Encrypt([]byte("hello"))
+	c2, _ := enc.Encrypt([]byte("hello"))
+
+	if bytes.Equal(c1[:32], c2[:32]) {
+		t.Error("each message should use a fresh ephemeral key")
+	}
+}
+
+func TestX25519_WrongKey(t *testing.T) {
+	priv1, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	priv2, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	enc1, _ := X25519(nil, priv1)
+	enc2, _ := X25519(nil, priv2)
+
+	ciphertext, _ := enc1.Encrypt([]byte("hello"))
+	if _, err := enc2.Decrypt(ciphertext); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt, got %v", err)
+	}
+
+	if _, err := enc1.Decrypt(ciphertext[:16]); !errors.Is(err, ErrCiphertextShort) {
+		t.Errorf("expected ErrCiphertextShort, got %v", err)
+	}
+}
+
+func TestX25519_InvalidKeys(t *testing.T) {
+	if _, err := X25519(nil, nil); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey for nil keys, got %v", err)
+	}
+
+	p256, _ := ecdh.P256().GenerateKey(rand.Reader)
+	if _, err := X25519(p256.PublicKey(), nil); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey for P-256 key, got %v", err)
+	}
+
+	priv1, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	priv2, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	if _, err := X25519(priv2.PublicKey(), priv1); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey for mismatched keys, got %v", err)
+	}
+}
+
+// X25519User has asymmetric encryption tags.
+type X25519User struct {
+	Email string `json:"email" store.encrypt:"x25519" load.decrypt:"x25519"`
+}
+
+func (u X25519User) Clone() X25519User { return u }
+
+// X25519WriteOnlyUser only encrypts on store.
+type X25519WriteOnlyUser struct {
+	Email string `json:"email" store.encrypt:"x25519,writeonly"`
+}
+
+func (u X25519WriteOnlyUser) Clone() X25519WriteOnlyUser { return u }
+
+func TestX25519_Processor(t *testing.T) {
+	priv, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	enc, _ := X25519(nil, priv)
+
+	proc, _ := NewProcessor[X25519User]()
+	proc.SetEncryptor(EncryptX25519, enc)
+
+	ctx := context.Background()
+	stored, err := proc.Store(ctx, X25519User{Email: testEmail})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+
+	loaded, err := proc.Load(ctx, stored)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if loaded.Email != testEmail {
+		t.Errorf("Email = %q, want %q", loaded.Email, testEmail)
+	}
+}
+
+func TestX25519_Processor_ValidateRejectsDecryptWithoutPrivateKey(t *testing.T) {
+	priv, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	enc, _ := X25519(priv.PublicKey(), nil)
+
+	proc, _ := NewProcessor[X25519User]()
+	proc.SetEncryptor(EncryptX25519, enc)
+
+	err := proc.Validate()
+	if !errors.Is(err, ErrDecryptUnsupported) {
+		t.Fatalf("expected ErrDecryptUnsupported, got %v", err)
+	}
+
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) || cfgErr.Field != "Email" {
+		t.Errorf("expected ConfigError for field Email, got %v", err)
+	}
+
+	// A write-only type accepts the public-key-only encryptor
+	writeOnly, _ := NewProcessor[X25519WriteOnlyUser]()
+	writeOnly.SetEncryptor(EncryptX25519, enc)
+	if err := writeOnly.Validate(); err != nil {
+		t.Errorf("Validate() error: %v", err)
+	}
+}